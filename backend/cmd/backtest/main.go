@@ -0,0 +1,130 @@
+// Comando backtest reprocessa o histórico de transações e compara as estratégias de scoring offline.
+//
+// Uso (a partir de backend/):
+//
+//	go run ./cmd/backtest -k 5 -passo 30 -horizonte 30 -estrategias padrao,legado
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+
+	"backend/interno/casodeuso"
+	"backend/interno/infraestrutura/configuracao"
+	"backend/interno/infraestrutura/logger"
+	"backend/interno/infraestrutura/repositorio"
+)
+
+func main() {
+	logger.InitLogger()
+
+	var (
+		inicio      = flag.String("inicio", "", "primeira data de corte (AAAA-MM-DD); padrão: primeira transação")
+		fim         = flag.String("fim", "", "última data de corte (AAAA-MM-DD); padrão: última transação")
+		passo       = flag.Int("passo", 30, "dias entre as datas de corte")
+		horizonte   = flag.Int("horizonte", 30, "dias após o corte em que as aplicações contam como acerto")
+		k           = flag.Int("k", 5, "tamanho da lista avaliada")
+		estrategias = flag.String("estrategias", "", "estratégias separadas por vírgula; padrão: todas")
+		configPath  = flag.String("config", "config/pontuacao.json", "arquivo de configuração de pontuação")
+		saidaJSON   = flag.Bool("json", false, "imprime o resultado em JSON")
+	)
+	flag.Parse()
+
+	if err := godotenv.Load("../.env"); err != nil {
+		slog.Warn("Aviso: arquivo .env não encontrado, usando variáveis de ambiente do sistema")
+	}
+
+	params := casodeuso.ParametrosBacktest{PassoDias: *passo, HorizonteDias: *horizonte, K: *k}
+	var err error
+	if params.Inicio, err = lerData(*inicio); err != nil {
+		sair("Data de início inválida", err)
+	}
+	if params.Fim, err = lerData(*fim); err != nil {
+		sair("Data de fim inválida", err)
+	}
+	for _, nome := range strings.Split(*estrategias, ",") {
+		if nome = strings.TrimSpace(nome); nome != "" {
+			params.Estrategias = append(params.Estrategias, nome)
+		}
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		getEnv("DB_HOST", "localhost"), getEnv("DB_PORT", "5432"), getEnv("DB_USER", "fiap"),
+		getEnv("DB_PASSWORD", "fiap123"), getEnv("DB_NAME", "tech_challenge"))
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		sair("Erro ao abrir conexão com banco de dados", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		sair("Erro ao conectar ao banco de dados", err)
+	}
+
+	repo := repositorio.NovoRepositorioPostgres(db)
+	// o backtest não publica mensagens, por isso não há publicador
+	servico := casodeuso.NovoServicoRecomendacao(repo, nil)
+
+	if _, err := os.Stat(*configPath); err == nil {
+		cfg, err := configuracao.CarregarConfigPontuacao(*configPath)
+		if err != nil {
+			sair("Erro ao carregar configuração de pontuação", err)
+		}
+		if err := servico.AtualizarConfig(cfg); err != nil {
+			sair("Erro ao aplicar configuração de pontuação", err)
+		}
+	} else {
+		slog.Warn("Arquivo de configuração de pontuação não encontrado, usando valores padrão", "arquivo", *configPath)
+	}
+
+	resultados, err := servico.Backtest(repo, params)
+	if err != nil {
+		sair("Erro ao executar backtest", err)
+	}
+
+	if *saidaJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(resultados); err != nil {
+			sair("Erro ao escrever o resultado", err)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ESTRATÉGIA\tAVALIAÇÕES\tPRECISION@%d\tRECALL@%d\tCOBERTURA\tDIVERSIDADE\n", *k, *k)
+	for _, r := range resultados {
+		fmt.Fprintf(w, "%s\t%d\t%.4f\t%.4f\t%.4f\t%.4f\n", r.Estrategia, r.Avaliacoes, r.PrecisaoK, r.RecallK, r.Cobertura, r.Diversidade)
+	}
+	if err := w.Flush(); err != nil {
+		sair("Erro ao escrever o resultado", err)
+	}
+}
+
+func lerData(valor string) (time.Time, error) {
+	if valor == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", valor)
+}
+
+func sair(mensagem string, err error) {
+	slog.Error(mensagem, "erro", err)
+	os.Exit(1)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -78,11 +78,6 @@ const docTemplate = `{
         },
         "/api/v2/auth/verify": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "description": "Verifica se o token JWT fornecido é válido",
                 "produces": [
                     "application/json"
@@ -108,22 +103,63 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/api/v2/healthcheck": {
+        "/api/v2/clientes/{id}/posicao": {
             "get": {
-                "description": "Retorna status OK se a API estiver no ar",
+                "description": "Retorna o saldo líquido (aplicações menos resgates concluídos) do cliente em cada produto",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "clientes"
+                ],
+                "summary": "Busca posição do cliente",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Verificação de saúde",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dominio.PosicaoCliente"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -131,30 +167,170 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/api/v2/recomendacoes": {
-            "post": {
+        "/api/v2/clientes/{id}/supressoes": {
+            "get": {
+                "description": "Retorna os produtos descartados pelo cliente que não voltam ao topo da recomendação até expirar a carência",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Lista supressões do cliente",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dominio.Supressao"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Dispara processo assíncrono para gerar recomendações para todos os clientes",
-                "consumes": [
+                ]
+            }
+        },
+        "/api/v2/clientes/{id}/supressoes/{produtoId}": {
+            "delete": {
+                "description": "Encerra a carência de um produto descartado pelo cliente antes do prazo. O usuário autenticado fica registrado como responsável.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "clientes"
+                ],
+                "summary": "Encerra supressão de produto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Cliente",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID do Produto",
+                        "name": "produtoId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v2/feedback/metricas": {
+            "get": {
+                "description": "Retorna, por produto e por regra, a contagem de itens visualizados, clicados, aceitos e descartados e a taxa de aceitação (aceitos / (aceitos + descartados))",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recomendacoes"
+                    "feedback"
+                ],
+                "summary": "Métricas de feedback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Considera eventos a partir da data (AAAA-MM-DD)",
+                        "name": "desde",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Gera recomendações em massa",
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dominio.MetricasFeedback"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -180,32 +356,52 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/api/v2/recomendacoes/{clienteId}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Retorna as últimas recomendações geradas para o cliente",
-                "consumes": [
+                ]
+            }
+        },
+        "/api/v2/healthcheck": {
+            "get": {
+                "description": "Retorna status OK se a API estiver no ar",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Verificação de saúde",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v2/jobs/{id}": {
+            "get": {
+                "description": "Retorna status (pendente, processando, concluido, falhou), datas, erro e o id da recomendação gerada",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recomendacoes"
+                    "jobs"
                 ],
-                "summary": "Busca recomendações recentes",
+                "summary": "Busca status de geração",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID do Cliente",
-                        "name": "clienteId",
+                        "description": "ID do Job",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -214,7 +410,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dominio.ResultadoRecomendacao"
+                            "$ref": "#/definitions/dominio.Job"
                         }
                     },
                     "401": {
@@ -234,38 +430,52 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Publica uma mensagem para gerar recomendações em background para o cliente informado",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/api/v2/lotes/{id}": {
+            "get": {
+                "description": "Retorna total de clientes, publicados, concluídos, falhos, progresso e estimativa de conclusão do lote",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recomendacoes"
+                    "lotes"
                 ],
-                "summary": "Solicita geração de recomendação",
+                "summary": "Progresso da geração em massa",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID do Cliente",
-                        "name": "clienteId",
+                        "description": "ID do Lote",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dominio.Lote"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -273,8 +483,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -291,11 +501,639 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v2/lotes/{id}/cancelar": {
+            "post": {
+                "description": "Interrompe a publicação e faz o worker descartar as mensagens ainda não processadas do lote",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lotes"
+                ],
+                "summary": "Cancela geração em massa",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Lote",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v2/recomendacoes": {
+            "post": {
+                "description": "Dispara processo assíncrono para gerar recomendações para todos os clientes, ou apenas para os do segmento informado no corpo.\nOs critérios do segmento são combinados entre si; critérios omitidos não filtram.\nA resposta traz o id_lote, acompanhado em GET /api/v2/lotes/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recomendacoes"
+                ],
+                "summary": "Gera recomendações em massa",
+                "parameters": [
+                    {
+                        "description": "Segmento de clientes (opcional)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controladores.GeracaoMassaRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade máxima de itens",
+                        "name": "limite",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Descarta itens com pontuação menor",
+                        "name": "pontuacao_minima",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por tipo de produto (aceita vários, separados por vírgula)",
+                        "name": "tipo_produto",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por risco: Baixo, Médio ou Alto (aceita vários, separados por vírgula)",
+                        "name": "risco",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v2/recomendacoes/{clienteId}": {
+            "get": {
+                "description": "Retorna as últimas recomendações geradas para o cliente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recomendacoes"
+                ],
+                "summary": "Busca recomendações recentes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Cliente",
+                        "name": "clienteId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade máxima de itens",
+                        "name": "limite",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Descarta itens com pontuação menor",
+                        "name": "pontuacao_minima",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por tipo de produto (aceita vários, separados por vírgula)",
+                        "name": "tipo_produto",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por risco: Baixo, Médio ou Alto (aceita vários, separados por vírgula)",
+                        "name": "risco",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Inclui a proposta de alocação por produto",
+                        "name": "alocacao",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dominio.ResultadoRecomendacao"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Publica uma mensagem para gerar recomendações em background para o cliente informado.\nOs filtros informados são enviados junto com a mensagem e aplicados na geração.\nCom modo=sincrono a geração roda na própria requisição e retorna 200 com o resultado; se o timeout\nfor excedido, responde 202 e a geração é repassada ao worker assíncrono.\nA resposta 202 traz o id_job, acompanhado em GET /api/v2/jobs/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recomendacoes"
+                ],
+                "summary": "Solicita geração de recomendação",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do Cliente",
+                        "name": "clienteId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade máxima de itens",
+                        "name": "limite",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Descarta itens com pontuação menor",
+                        "name": "pontuacao_minima",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por tipo de produto (aceita vários, separados por vírgula)",
+                        "name": "tipo_produto",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por risco: Baixo, Médio ou Alto (aceita vários, separados por vírgula)",
+                        "name": "risco",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "assincrono",
+                            "sincrono"
+                        ],
+                        "type": "string",
+                        "description": "assincrono (padrão) ou sincrono",
+                        "name": "modo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Prazo do modo síncrono (ex: 3s, 500ms; padrão 5s, máximo 12s)",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dominio.ResultadoRecomendacao"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v2/recomendacoes/{id}/feedback": {
+            "post": {
+                "description": "Registra eventos por item (visualizado, clicado, aceito, descartado) vinculados ao id da recomendação",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feedback"
+                ],
+                "summary": "Registra feedback de uma recomendação",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da Recomendação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Eventos de feedback",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controladores.FeedbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/api/v2/simulacoes": {
+            "post": {
+                "description": "Roda o mesmo scoring da geração contra o catálogo atual para um perfil, posições e interações hipotéticos. O resultado não é persistido.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "simulacoes"
+                ],
+                "summary": "Simula recomendações",
+                "parameters": [
+                    {
+                        "description": "Cliente hipotético",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controladores.SimulacaoRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade máxima de itens",
+                        "name": "limite",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Descarta itens com pontuação menor",
+                        "name": "pontuacao_minima",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por tipo de produto (aceita vários, separados por vírgula)",
+                        "name": "tipo_produto",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por risco: Baixo, Médio ou Alto (aceita vários, separados por vírgula)",
+                        "name": "risco",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Inclui a proposta de alocação por produto",
+                        "name": "alocacao",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dominio.ResultadoRecomendacao"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
     },
     "definitions": {
+        "controladores.EventoFeedbackRequest": {
+            "type": "object",
+            "required": [
+                "id_produto",
+                "tipo"
+            ],
+            "properties": {
+                "id_produto": {
+                    "type": "string"
+                },
+                "tipo": {
+                    "type": "string",
+                    "enum": [
+                        "visualizado",
+                        "clicado",
+                        "aceito",
+                        "descartado"
+                    ],
+                    "example": "aceito"
+                }
+            }
+        },
+        "controladores.FeedbackRequest": {
+            "type": "object",
+            "required": [
+                "eventos"
+            ],
+            "properties": {
+                "eventos": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/controladores.EventoFeedbackRequest"
+                    }
+                }
+            }
+        },
+        "controladores.GeracaoMassaRequest": {
+            "type": "object",
+            "properties": {
+                "segmento": {
+                    "$ref": "#/definitions/dominio.SegmentoClientes"
+                }
+            }
+        },
         "controladores.LoginRequest": {
             "type": "object",
             "required": [
@@ -305,37 +1143,385 @@ const docTemplate = `{
             "properties": {
                 "email": {
                     "type": "string",
-                    "example": "usuario@exemplo.com"
+                    "example": "usuario@exemplo.com"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "senha123"
+                }
+            }
+        },
+        "controladores.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "usuario@exemplo.com"
+                },
+                "expiresIn": {
+                    "type": "string",
+                    "example": "3600"
+                },
+                "idToken": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJSUzI1NiIsImtpZCI6IjEyMzQ1Njc4OTAifQ..."
+                },
+                "refreshToken": {
+                    "type": "string",
+                    "example": "..."
+                },
+                "uid": {
+                    "type": "string",
+                    "example": "abc123def456"
+                }
+            }
+        },
+        "controladores.PosicaoSimulada": {
+            "type": "object",
+            "required": [
+                "id_produto"
+            ],
+            "properties": {
+                "id_produto": {
+                    "type": "string"
+                },
+                "saldo": {
+                    "type": "number",
+                    "example": 10000
+                }
+            }
+        },
+        "controladores.SimulacaoRequest": {
+            "type": "object",
+            "properties": {
+                "estrategia": {
+                    "description": "vazio usa a estratégia padrão",
+                    "type": "string",
+                    "example": "padrao"
+                },
+                "interacoes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dominio.Interacao"
+                    }
+                },
+                "objetivo_investimento": {
+                    "type": "string",
+                    "example": "Crescimento de Capital"
                 },
-                "password": {
+                "patrimonio_total_estimado": {
+                    "type": "number",
+                    "example": 500000
+                },
+                "perfil_risco": {
                     "type": "string",
-                    "minLength": 6,
-                    "example": "senha123"
+                    "example": "Arrojado"
+                },
+                "posicoes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controladores.PosicaoSimulada"
+                    }
                 }
             }
         },
-        "controladores.LoginResponse": {
+        "dominio.Alocacao": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "usuario@exemplo.com"
+                "itens": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dominio.ItemAlocacao"
+                    }
                 },
-                "expiresIn": {
+                "montante_base": {
+                    "description": "parcela do patrimônio considerada na proposta",
+                    "type": "number"
+                },
+                "valor_alocado": {
+                    "type": "number"
+                },
+                "valor_nao_alocado": {
+                    "description": "sobra que não coube nos mínimos e tetos",
+                    "type": "number"
+                }
+            }
+        },
+        "dominio.ContribuicaoRegra": {
+            "type": "object",
+            "properties": {
+                "descricao": {
+                    "type": "string"
+                },
+                "entradas": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "pontos": {
+                    "type": "number"
+                },
+                "regra_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dominio.ExclusaoProduto": {
+            "type": "object",
+            "properties": {
+                "entradas": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "filtro_id": {
+                    "type": "string"
+                },
+                "id_produto": {
+                    "type": "string"
+                },
+                "motivo": {
+                    "type": "string"
+                },
+                "nome_produto": {
+                    "type": "string"
+                }
+            }
+        },
+        "dominio.FiltroItens": {
+            "type": "object",
+            "properties": {
+                "limite": {
+                    "description": "0 = sem limite",
+                    "type": "integer"
+                },
+                "pontuacao_minima": {
+                    "description": "itens abaixo são descartados",
+                    "type": "number"
+                },
+                "riscos": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tipos_produto": {
+                    "description": "vazio = todos",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dominio.Interacao": {
+            "type": "object",
+            "properties": {
+                "data_interacao": {
+                    "type": "string"
+                },
+                "duracao_interacao_segundos": {
+                    "type": "integer"
+                },
+                "id_produto": {
+                    "type": "string"
+                },
+                "tipo_interacao": {
+                    "type": "string"
+                }
+            }
+        },
+        "dominio.ItemAlocacao": {
+            "type": "object",
+            "properties": {
+                "id_produto": {
+                    "type": "string"
+                },
+                "nome_produto": {
+                    "type": "string"
+                },
+                "percentual": {
+                    "description": "em relação ao montante base",
+                    "type": "number"
+                },
+                "risco_associado": {
                     "type": "string",
-                    "example": "3600"
+                    "example": "Médio"
                 },
-                "idToken": {
+                "valor": {
+                    "type": "number"
+                }
+            }
+        },
+        "dominio.Job": {
+            "type": "object",
+            "properties": {
+                "criado_em": {
+                    "type": "string"
+                },
+                "erro": {
+                    "type": "string"
+                },
+                "finalizado_em": {
+                    "type": "string"
+                },
+                "id_cliente": {
+                    "type": "string"
+                },
+                "id_job": {
+                    "type": "string"
+                },
+                "id_recomendacao": {
+                    "description": "preenchido quando concluído",
+                    "type": "string"
+                },
+                "iniciado_em": {
+                    "type": "string"
+                },
+                "status": {
                     "type": "string",
-                    "example": "eyJhbGciOiJSUzI1NiIsImtpZCI6IjEyMzQ1Njc4OTAifQ..."
+                    "enum": [
+                        "pendente",
+                        "processando",
+                        "concluido",
+                        "falhou"
+                    ]
+                }
+            }
+        },
+        "dominio.Lote": {
+            "type": "object",
+            "properties": {
+                "concluidos": {
+                    "type": "integer"
                 },
-                "refreshToken": {
+                "criado_em": {
+                    "type": "string"
+                },
+                "estimativa_conclusao": {
+                    "description": "pelo ritmo médio até agora",
+                    "type": "string"
+                },
+                "falhos": {
+                    "type": "integer"
+                },
+                "finalizado_em": {
+                    "type": "string"
+                },
+                "id_lote": {
+                    "type": "string"
+                },
+                "opcoes": {
+                    "$ref": "#/definitions/dominio.FiltroItens"
+                },
+                "progresso": {
+                    "description": "calculados na consulta a partir dos contadores",
+                    "type": "number"
+                },
+                "publicacao_finalizada": {
+                    "description": "PublicacaoFinalizada indica que todas as mensagens foram publicadas; até lá Total é uma estimativa",
+                    "type": "boolean"
+                },
+                "publicados": {
+                    "type": "integer"
+                },
+                "segmento": {
+                    "description": "clientes incluídos no lote",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dominio.SegmentoClientes"
+                        }
+                    ]
+                },
+                "status": {
                     "type": "string",
-                    "example": "..."
+                    "enum": [
+                        "em_andamento",
+                        "concluido",
+                        "cancelado"
+                    ]
                 },
-                "uid": {
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dominio.MetricaFeedback": {
+            "type": "object",
+            "properties": {
+                "aceitos": {
+                    "type": "integer"
+                },
+                "chave": {
+                    "description": "id do produto ou da regra",
+                    "type": "string"
+                },
+                "clicados": {
+                    "type": "integer"
+                },
+                "descartados": {
+                    "type": "integer"
+                },
+                "taxa_aceitacao": {
+                    "description": "aceitos / (aceitos + descartados)",
+                    "type": "number"
+                },
+                "visualizados": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dominio.MetricasFeedback": {
+            "type": "object",
+            "properties": {
+                "por_produto": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dominio.MetricaFeedback"
+                    }
+                },
+                "por_regra": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dominio.MetricaFeedback"
+                    }
+                }
+            }
+        },
+        "dominio.Posicao": {
+            "type": "object",
+            "properties": {
+                "id_produto": {
+                    "type": "string"
+                },
+                "nome_produto": {
+                    "type": "string"
+                },
+                "risco_associado": {
                     "type": "string",
-                    "example": "abc123def456"
+                    "example": "Médio"
+                },
+                "saldo": {
+                    "type": "number"
+                },
+                "tipo_produto": {
+                    "type": "string"
+                }
+            }
+        },
+        "dominio.PosicaoCliente": {
+            "type": "object",
+            "properties": {
+                "id_cliente": {
+                    "type": "string"
+                },
+                "posicoes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dominio.Posicao"
+                    }
+                },
+                "saldo_total": {
+                    "type": "number"
                 }
             }
         },
@@ -348,13 +1534,28 @@ const docTemplate = `{
                 "id_produto": {
                     "type": "string"
                 },
+                "liquidez": {
+                    "description": "ex: D+0, D+30",
+                    "type": "string"
+                },
                 "nome_produto": {
                     "type": "string"
                 },
                 "rentabilidade_12m": {
                     "type": "number"
                 },
+                "rentabilidade_36m": {
+                    "description": "acumulada no período, em %",
+                    "type": "number"
+                },
                 "risco_associado": {
+                    "type": "string",
+                    "example": "Médio"
+                },
+                "taxa_administracao": {
+                    "type": "number"
+                },
+                "tipo_produto": {
                     "type": "string"
                 }
             }
@@ -362,7 +1563,15 @@ const docTemplate = `{
         "dominio.RecomendacaoItem": {
             "type": "object",
             "properties": {
+                "explicacao": {
+                    "description": "detalhamento auditável da pontuação",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dominio.ContribuicaoRegra"
+                    }
+                },
                 "motivo": {
+                    "description": "resumo legível, mantido por compatibilidade",
                     "type": "string"
                 },
                 "pontuacao": {
@@ -376,6 +1585,20 @@ const docTemplate = `{
         "dominio.ResultadoRecomendacao": {
             "type": "object",
             "properties": {
+                "alocacao": {
+                    "$ref": "#/definitions/dominio.Alocacao"
+                },
+                "estrategia": {
+                    "description": "estratégia de scoring usada (teste A/B)",
+                    "type": "string"
+                },
+                "exclusoes": {
+                    "description": "produtos barrados pelos filtros de elegibilidade",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dominio.ExclusaoProduto"
+                    }
+                },
                 "id_cliente": {
                     "type": "string"
                 },
@@ -388,6 +1611,79 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/dominio.RecomendacaoItem"
                     }
+                },
+                "variante": {
+                    "description": "grupo do experimento em que o cliente caiu",
+                    "type": "string"
+                },
+                "versao_config": {
+                    "description": "versão dos pesos/limiares que gerou o resultado",
+                    "type": "string"
+                }
+            }
+        },
+        "dominio.SegmentoClientes": {
+            "type": "object",
+            "properties": {
+                "ids_clientes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "objetivos": {
+                    "description": "objetivo_investimento, sem diferenciar caixa",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Aposentadoria"
+                    ]
+                },
+                "patrimonio_maximo": {
+                    "type": "number",
+                    "example": 1000000
+                },
+                "patrimonio_minimo": {
+                    "type": "number",
+                    "example": 100000
+                },
+                "perfis_risco": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Moderado",
+                        "Arrojado"
+                    ]
+                },
+                "sem_recomendacao_dias": {
+                    "description": "SemRecomendacaoDias mantém apenas clientes sem recomendação gerada nos últimos N dias",
+                    "type": "integer",
+                    "example": 30
+                }
+            }
+        },
+        "dominio.Supressao": {
+            "type": "object",
+            "properties": {
+                "expira_em": {
+                    "type": "string"
+                },
+                "id_cliente": {
+                    "type": "string"
+                },
+                "id_produto": {
+                    "type": "string"
+                },
+                "id_recomendacao": {
+                    "description": "recomendação em que o item foi descartado",
+                    "type": "string"
+                },
+                "inicio": {
+                    "type": "string"
                 }
             }
         }
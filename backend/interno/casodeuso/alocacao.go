@@ -0,0 +1,99 @@
+package casodeuso
+
+import (
+	"math"
+
+	"backend/interno/dominio"
+)
+
+// CalcularAlocacao distribui o montante de referência do cliente entre os itens recomendados.
+// Cada produto recebe uma fatia proporcional à sua pontuação, respeitando a aplicação mínima,
+// o teto por produto e o teto por classe de risco do perfil. O que não couber em nenhum
+// produto fica como valor não alocado.
+func CalcularAlocacao(itens []dominio.RecomendacaoItem, cliente *dominio.Cliente, cfg ConfigAlocacao) *dominio.Alocacao {
+	montante := cliente.Patrimonio * cfg.FracaoPatrimonio
+	alocacao := &dominio.Alocacao{MontanteBase: arredondar(montante), Itens: []dominio.ItemAlocacao{}}
+	if montante <= 0 || len(itens) == 0 {
+		alocacao.ValorNaoAlocado = alocacao.MontanteBase
+		return alocacao
+	}
+
+	somaPontuacao := 0.0
+	for _, item := range itens {
+		if item.Pontuacao > 0 {
+			somaPontuacao += item.Pontuacao
+		}
+	}
+	if somaPontuacao == 0 {
+		alocacao.ValorNaoAlocado = alocacao.MontanteBase
+		return alocacao
+	}
+
+	// saldo disponível por classe de risco segundo o perfil do cliente
+	disponivelRisco := make(map[dominio.NivelRisco]float64)
+	for nivel, fracao := range cfg.LimitesRisco[cliente.PerfilRisco] {
+		disponivelRisco[nivel] = montante * fracao
+	}
+	tetoProduto := montante * cfg.MaximoPorProduto
+	restante := montante
+
+	valores := make([]float64, len(itens))
+
+	// 1ª passada: fatia proporcional à pontuação, elevada à aplicação mínima quando couber
+	for i, item := range itens {
+		if item.Pontuacao <= 0 {
+			continue
+		}
+		risco := item.Produto.RiscoAssociado
+		limite := math.Min(math.Min(tetoProduto, disponivelRisco[risco]), restante)
+
+		valor := math.Min(montante*item.Pontuacao/somaPontuacao, limite)
+		if valor < item.Produto.AplicacaoMinima {
+			if item.Produto.AplicacaoMinima > limite {
+				continue // não há espaço para a aplicação mínima deste produto
+			}
+			valor = item.Produto.AplicacaoMinima
+		}
+
+		valores[i] = valor
+		disponivelRisco[risco] -= valor
+		restante -= valor
+	}
+
+	// 2ª passada: redistribui a sobra (produtos descartados e tetos) seguindo o ranking
+	for i, item := range itens {
+		if valores[i] == 0 || restante <= 0 {
+			continue
+		}
+		risco := item.Produto.RiscoAssociado
+		extra := math.Min(math.Min(tetoProduto-valores[i], disponivelRisco[risco]), restante)
+		if extra > 0 {
+			valores[i] += extra
+			disponivelRisco[risco] -= extra
+			restante -= extra
+		}
+	}
+
+	for i, item := range itens {
+		if valores[i] == 0 {
+			continue
+		}
+		alocacao.Itens = append(alocacao.Itens, dominio.ItemAlocacao{
+			ProdutoID:      item.Produto.ID,
+			NomeProduto:    item.Produto.Nome,
+			RiscoAssociado: item.Produto.RiscoAssociado,
+			Valor:          arredondar(valores[i]),
+			Percentual:     arredondar(valores[i] / montante * 100),
+		})
+		alocacao.ValorAlocado += valores[i]
+	}
+	alocacao.ValorAlocado = arredondar(alocacao.ValorAlocado)
+	alocacao.ValorNaoAlocado = arredondar(montante - alocacao.ValorAlocado)
+
+	return alocacao
+}
+
+// arredondar mantém valores monetários e percentuais com duas casas decimais
+func arredondar(v float64) float64 {
+	return math.Round(v*100) / 100
+}
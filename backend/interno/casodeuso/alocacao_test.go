@@ -0,0 +1,97 @@
+package casodeuso
+
+import (
+	"testing"
+
+	"backend/interno/dominio"
+)
+
+func itemAlocacao(id string, risco dominio.NivelRisco, pontuacao, aplicacaoMinima float64) dominio.RecomendacaoItem {
+	return dominio.RecomendacaoItem{
+		Produto:   dominio.Produto{ID: id, RiscoAssociado: risco, AplicacaoMinima: aplicacaoMinima},
+		Pontuacao: pontuacao,
+	}
+}
+
+func TestCalcularAlocacao(t *testing.T) {
+	casos := []struct {
+		nome       string
+		patrimonio float64
+		itens      []dominio.RecomendacaoItem
+		valores    map[string]float64
+		naoAlocado float64
+	}{
+		{
+			nome:       "sem patrimônio nada é alocado",
+			patrimonio: 0,
+			itens:      []dominio.RecomendacaoItem{itemAlocacao("a", dominio.RiscoBaixo, 1, 0)},
+			valores:    map[string]float64{},
+		},
+		{
+			nome:       "teto por produto redistribui a sobra pelo ranking",
+			patrimonio: 10000,
+			itens: []dominio.RecomendacaoItem{
+				itemAlocacao("a", dominio.RiscoBaixo, 0.5, 0),
+				itemAlocacao("b", dominio.RiscoBaixo, 0.3, 0),
+				itemAlocacao("c", dominio.RiscoBaixo, 0.2, 0),
+			},
+			valores: map[string]float64{"a": 4000, "b": 4000, "c": 2000},
+		},
+		{
+			nome:       "limite de risco do perfil sobra como não alocado",
+			patrimonio: 10000,
+			itens: []dominio.RecomendacaoItem{
+				itemAlocacao("a", dominio.RiscoMedio, 0.5, 0),
+				itemAlocacao("b", dominio.RiscoMedio, 0.5, 0),
+			},
+			valores:    map[string]float64{"a": 4000, "b": 2000},
+			naoAlocado: 4000,
+		},
+		{
+			nome:       "aplicação mínima acima do espaço descarta o produto",
+			patrimonio: 10000,
+			itens: []dominio.RecomendacaoItem{
+				itemAlocacao("a", dominio.RiscoBaixo, 0.9, 0),
+				itemAlocacao("b", dominio.RiscoBaixo, 0.1, 5000),
+			},
+			valores:    map[string]float64{"a": 4000},
+			naoAlocado: 6000,
+		},
+		{
+			nome:       "pontuação não positiva não recebe valor",
+			patrimonio: 10000,
+			itens: []dominio.RecomendacaoItem{
+				itemAlocacao("a", dominio.RiscoBaixo, 1, 0),
+				itemAlocacao("b", dominio.RiscoBaixo, -0.2, 0),
+			},
+			valores:    map[string]float64{"a": 4000},
+			naoAlocado: 6000,
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			cliente := &dominio.Cliente{PerfilRisco: dominio.PerfilModerado, Patrimonio: c.patrimonio}
+			alocacao := CalcularAlocacao(c.itens, cliente, ConfigPadrao().Alocacao)
+
+			if len(alocacao.Itens) != len(c.valores) {
+				t.Fatalf("itens = %+v, esperado %v", alocacao.Itens, c.valores)
+			}
+			for _, item := range alocacao.Itens {
+				if !quaseIgual(item.Valor, c.valores[item.ProdutoID]) {
+					t.Errorf("valor de %s = %v, esperado %v", item.ProdutoID, item.Valor, c.valores[item.ProdutoID])
+				}
+				if !quaseIgual(item.Percentual, item.Valor/c.patrimonio*100) {
+					t.Errorf("percentual de %s = %v", item.ProdutoID, item.Percentual)
+				}
+			}
+			if !quaseIgual(alocacao.ValorNaoAlocado, c.naoAlocado) {
+				t.Errorf("não alocado = %v, esperado %v", alocacao.ValorNaoAlocado, c.naoAlocado)
+			}
+			if !quaseIgual(alocacao.ValorAlocado+alocacao.ValorNaoAlocado, alocacao.MontanteBase) {
+				t.Errorf("alocado %v + não alocado %v difere do montante %v",
+					alocacao.ValorAlocado, alocacao.ValorNaoAlocado, alocacao.MontanteBase)
+			}
+		})
+	}
+}
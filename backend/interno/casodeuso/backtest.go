@@ -0,0 +1,291 @@
+package casodeuso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// ParametrosBacktest define a janela e a granularidade da avaliação offline
+type ParametrosBacktest struct {
+	Inicio, Fim   time.Time // zero usa a primeira e a última transação do histórico
+	PassoDias     int       // intervalo entre as datas de corte
+	HorizonteDias int       // período após o corte em que as aplicações contam como acerto
+	K             int       // tamanho da lista avaliada (precision@k e recall@k)
+	Estrategias   []string  // vazio avalia todas as estratégias registradas
+}
+
+// ResultadoBacktest resume as métricas de uma estratégia em todas as datas de corte.
+//
+// A reconstrução no corte é parcial: o catálogo (produtos ativos e seus atributos) e o
+// perfil do cliente são os atuais, não os da data avaliada, e as supressões de produtos
+// descartados são ignoradas. Precisão e recall comparam estratégias entre si sobre a mesma
+// base, mas não reproduzem o que o cliente teria visto naquela data.
+type ResultadoBacktest struct {
+	Estrategia  string  `json:"estrategia"`
+	Avaliacoes  int     `json:"avaliacoes"`  // pares (cliente, data de corte) com ao menos uma aplicação futura
+	PrecisaoK   float64 `json:"precisao_k"`  // média de acertos / k
+	RecallK     float64 `json:"recall_k"`    // média de acertos / produtos aplicados no horizonte
+	Cobertura   float64 `json:"cobertura"`   // fração do catálogo que apareceu em algum top-k
+	Diversidade float64 `json:"diversidade"` // média de 1 - similaridade entre pares do top-k
+}
+
+// acumuladorBacktest soma as métricas de uma estratégia ao longo da avaliação
+type acumuladorBacktest struct {
+	avaliacoes       int
+	precisao, recall float64
+	listasDiversas   int
+	diversidade      float64
+	recomendados     map[string]bool
+}
+
+// Backtest reprocessa o histórico em ordem cronológica: em cada data de corte monta a posição
+// e as interações conhecidas até aquele momento, gera as recomendações de cada estratégia e
+// compara o top-k com os produtos que o cliente aplicou no horizonte seguinte. Produtos que o
+// cliente já possuía na data de corte não contam como acerto, pois não seriam uma descoberta.
+// O catálogo usado é o atual, já que o histórico de atributos dos produtos não é versionado.
+func (s *ServicoRecomendacao) Backtest(historico dominio.RepositorioHistorico, params ParametrosBacktest) ([]ResultadoBacktest, error) {
+	if params.PassoDias <= 0 || params.HorizonteDias <= 0 || params.K <= 0 {
+		return nil, errors.New("passo, horizonte e k devem ser positivos")
+	}
+
+	estrategias := params.Estrategias
+	if len(estrategias) == 0 {
+		estrategias = s.estrategias.Nomes()
+	}
+	regrasPorEstrategia := make(map[string][]RegraPontuacao, len(estrategias))
+	for _, nome := range estrategias {
+		registro, existe := s.estrategias.Obter(nome)
+		if !existe {
+			return nil, fmt.Errorf("estratégia não registrada: %s", nome)
+		}
+		regrasPorEstrategia[nome] = registro.Ativas()
+	}
+
+	// a avaliação offline precisa de todos os clientes em memória para reprocessar cada data de corte
+	var clientes []dominio.Cliente
+	for cursor := ""; ; {
+		pagina, err := s.repo.ListarClientesApos(cursor, tamanhoPaginaClientes, dominio.SegmentoClientes{})
+		if err != nil {
+			return nil, err
+		}
+		if len(pagina) == 0 {
+			break
+		}
+		clientes = append(clientes, pagina...)
+		cursor = pagina[len(pagina)-1].ID
+	}
+	produtos, err := s.repo.ListarProdutosAtivos(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	transacoes, err := historico.ListarTransacoesConcluidas()
+	if err != nil {
+		return nil, err
+	}
+	interacoes, err := historico.ListarInteracoesPorCliente()
+	if err != nil {
+		return nil, err
+	}
+	if len(transacoes) == 0 {
+		return nil, errors.New("nenhuma transação concluída no histórico")
+	}
+
+	// garante a ordem cronológica mesmo se o repositório não ordenar
+	sort.SliceStable(transacoes, func(i, j int) bool { return transacoes[i].Data.Before(transacoes[j].Data) })
+
+	inicio, fim := params.Inicio, params.Fim
+	if inicio.IsZero() {
+		inicio = transacoes[0].Data
+	}
+	if fim.IsZero() {
+		fim = transacoes[len(transacoes)-1].Data
+	}
+
+	catalogo := make(map[string]dominio.Produto, len(produtos))
+	for _, p := range produtos {
+		catalogo[p.ID] = p
+	}
+
+	cfg := s.ConfigAtual()
+	acumuladores := make(map[string]*acumuladorBacktest, len(estrategias))
+	for _, nome := range estrategias {
+		acumuladores[nome] = &acumuladorBacktest{recomendados: make(map[string]bool)}
+	}
+
+	// saldos[cliente][produto] é atualizado incrementalmente conforme o corte avança
+	saldos := make(map[string]map[string]float64)
+	proxima := 0
+
+	for corte := inicio; !corte.After(fim); corte = corte.AddDate(0, 0, params.PassoDias) {
+		for ; proxima < len(transacoes) && transacoes[proxima].Data.Before(corte); proxima++ {
+			aplicarTransacao(saldos, transacoes[proxima])
+		}
+		limite := corte.AddDate(0, 0, params.HorizonteDias)
+		futuras := aplicacoesNoPeriodo(transacoes[proxima:], limite)
+
+		for i := range clientes {
+			cliente := &clientes[i]
+			posicoes := posicoesDoSaldo(saldos[cliente.ID], catalogo)
+
+			relevantes := make(map[string]bool)
+			for produtoID := range futuras[cliente.ID] {
+				if saldos[cliente.ID][produtoID] <= 0 {
+					relevantes[produtoID] = true
+				}
+			}
+			if len(relevantes) == 0 {
+				continue
+			}
+
+			desde := corte.AddDate(0, 0, -cfg.Interesse.JanelaDias)
+			ctx := novoContextoPontuacao(cliente, cfg, corte, posicoes, interacoesNoPeriodo(interacoes[cliente.ID], desde, corte))
+			ctx.ProdutosAplicados, ctx.ProdutosInteragidos = historicoAte(saldos[cliente.ID], interacoes[cliente.ID], corte)
+
+			for _, nome := range estrategias {
+				itens, _ := s.calcular(ctx, regrasPorEstrategia[nome], produtos, dominio.FiltroItens{Limite: params.K})
+				acumuladores[nome].registrar(itens, relevantes, params.K, cfg.Reranqueamento)
+			}
+		}
+	}
+
+	resultados := make([]ResultadoBacktest, 0, len(estrategias))
+	for _, nome := range estrategias {
+		resultado := acumuladores[nome].resumir(nome, len(produtos))
+		slog.Info("Backtest da estratégia concluído",
+			"estrategia", nome,
+			"avaliacoes", resultado.Avaliacoes,
+			"precisao_k", resultado.PrecisaoK,
+			"recall_k", resultado.RecallK,
+		)
+		resultados = append(resultados, resultado)
+	}
+	return resultados, nil
+}
+
+func (a *acumuladorBacktest) registrar(itens []dominio.RecomendacaoItem, relevantes map[string]bool, k int, cfg ConfigReranqueamento) {
+	acertos := 0
+	for _, item := range itens {
+		a.recomendados[item.Produto.ID] = true
+		if relevantes[item.Produto.ID] {
+			acertos++
+		}
+	}
+	a.avaliacoes++
+	a.precisao += float64(acertos) / float64(k)
+	a.recall += float64(acertos) / float64(len(relevantes))
+
+	// diversidade intra-lista só faz sentido com ao menos dois itens
+	if len(itens) < 2 {
+		return
+	}
+	soma, pares := 0.0, 0
+	for i := 0; i < len(itens); i++ {
+		for j := i + 1; j < len(itens); j++ {
+			soma += 1 - similaridade(itens[i].Produto, itens[j].Produto, cfg)
+			pares++
+		}
+	}
+	a.listasDiversas++
+	a.diversidade += soma / float64(pares)
+}
+
+func (a *acumuladorBacktest) resumir(estrategia string, totalProdutos int) ResultadoBacktest {
+	resultado := ResultadoBacktest{Estrategia: estrategia, Avaliacoes: a.avaliacoes}
+	if a.avaliacoes > 0 {
+		resultado.PrecisaoK = a.precisao / float64(a.avaliacoes)
+		resultado.RecallK = a.recall / float64(a.avaliacoes)
+	}
+	if a.listasDiversas > 0 {
+		resultado.Diversidade = a.diversidade / float64(a.listasDiversas)
+	}
+	if totalProdutos > 0 {
+		resultado.Cobertura = float64(len(a.recomendados)) / float64(totalProdutos)
+	}
+	return resultado
+}
+
+// aplicarTransacao atualiza o saldo do cliente no produto
+func aplicarTransacao(saldos map[string]map[string]float64, t dominio.Transacao) {
+	porProduto, existe := saldos[t.ClienteID]
+	if !existe {
+		porProduto = make(map[string]float64)
+		saldos[t.ClienteID] = porProduto
+	}
+	switch t.Tipo {
+	case dominio.TipoTransacaoAplicacao:
+		porProduto[t.ProdutoID] += t.Valor
+	case dominio.TipoTransacaoResgate:
+		porProduto[t.ProdutoID] -= t.Valor
+	}
+}
+
+// aplicacoesNoPeriodo agrupa por cliente os produtos aplicados antes do limite.
+// As transações recebidas já começam na data de corte.
+func aplicacoesNoPeriodo(transacoes []dominio.Transacao, limite time.Time) map[string]map[string]bool {
+	porCliente := make(map[string]map[string]bool)
+	for _, t := range transacoes {
+		if !t.Data.Before(limite) {
+			break
+		}
+		if t.Tipo != dominio.TipoTransacaoAplicacao {
+			continue
+		}
+		if porCliente[t.ClienteID] == nil {
+			porCliente[t.ClienteID] = make(map[string]bool)
+		}
+		porCliente[t.ClienteID][t.ProdutoID] = true
+	}
+	return porCliente
+}
+
+// posicoesDoSaldo converte os saldos positivos em posições com os dados do catálogo
+func posicoesDoSaldo(saldos map[string]float64, catalogo map[string]dominio.Produto) []dominio.Posicao {
+	var posicoes []dominio.Posicao
+	for produtoID, saldo := range saldos {
+		if saldo <= 0 {
+			continue
+		}
+		prod := catalogo[produtoID]
+		posicoes = append(posicoes, dominio.Posicao{
+			ProdutoID:      produtoID,
+			NomeProduto:    prod.Nome,
+			TipoProduto:    prod.TipoProduto,
+			RiscoAssociado: prod.RiscoAssociado,
+			Saldo:          saldo,
+		})
+	}
+	return posicoes
+}
+
+// interacoesNoPeriodo mantém apenas as interações em [desde, ate)
+func interacoesNoPeriodo(interacoes []dominio.Interacao, desde, ate time.Time) []dominio.Interacao {
+	var periodo []dominio.Interacao
+	for _, i := range interacoes {
+		if !i.Data.Before(desde) && i.Data.Before(ate) {
+			periodo = append(periodo, i)
+		}
+	}
+	return periodo
+}
+
+// historicoAte reconstrói o histórico completo visto no corte: todo produto com saldo
+// registrado já recebeu aplicação, e toda interação anterior conta, sem janela
+func historicoAte(saldos map[string]float64, interacoes []dominio.Interacao, corte time.Time) (map[string]bool, map[string]bool) {
+	aplicados := make(map[string]bool, len(saldos))
+	for produtoID := range saldos {
+		aplicados[produtoID] = true
+	}
+	interagidos := make(map[string]bool)
+	for _, i := range interacoes {
+		if i.Data.Before(corte) {
+			interagidos[i.ProdutoID] = true
+		}
+	}
+	return aplicados, interagidos
+}
@@ -0,0 +1,157 @@
+package casodeuso
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"backend/interno/dominio"
+)
+
+func TestBacktestParametrosInvalidos(t *testing.T) {
+	casos := []struct {
+		nome   string
+		params ParametrosBacktest
+	}{
+		{"passo zero", ParametrosBacktest{HorizonteDias: 30, K: 5}},
+		{"horizonte zero", ParametrosBacktest{PassoDias: 30, K: 5}},
+		{"k zero", ParametrosBacktest{PassoDias: 30, HorizonteDias: 30}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if _, err := (&ServicoRecomendacao{}).Backtest(nil, c.params); err == nil {
+				t.Error("parâmetros deveriam ser rejeitados")
+			}
+		})
+	}
+}
+
+func TestAcumuladorBacktest(t *testing.T) {
+	cfg := ConfigReranqueamento{PesoTipo: 0.7, PesoRisco: 0.3}
+	acumulador := &acumuladorBacktest{recomendados: make(map[string]bool)}
+
+	// 1 acerto em k=2 com 2 relevantes; CDB baixo x fundo alto não têm nada em comum
+	acumulador.registrar([]dominio.RecomendacaoItem{
+		itemTeste("cdb", "CDB", dominio.RiscoBaixo, 1),
+		itemTeste("acoes", "Fundo de Ações", dominio.RiscoAlto, 0.5),
+	}, map[string]bool{"cdb": true, "lci": true}, 2, cfg)
+
+	// 2 acertos em k=2 com 2 relevantes; dois CDBs de risco baixo são idênticos
+	acumulador.registrar([]dominio.RecomendacaoItem{
+		itemTeste("cdb", "CDB", dominio.RiscoBaixo, 1),
+		itemTeste("cdb2", "CDB", dominio.RiscoBaixo, 0.5),
+	}, map[string]bool{"cdb": true, "cdb2": true}, 2, cfg)
+
+	// lista com um item entra na precisão, mas não na diversidade
+	acumulador.registrar([]dominio.RecomendacaoItem{
+		itemTeste("lci", "LCI/LCA", dominio.RiscoBaixo, 1),
+	}, map[string]bool{"acoes": true}, 2, cfg)
+
+	resultado := acumulador.resumir("padrao", 8)
+
+	esperado := ResultadoBacktest{
+		Estrategia:  "padrao",
+		Avaliacoes:  3,
+		PrecisaoK:   (0.5 + 1 + 0) / 3,
+		RecallK:     (0.5 + 1 + 0) / 3,
+		Cobertura:   4.0 / 8,
+		Diversidade: (1 + 0) / 2.0,
+	}
+	if resultado.Estrategia != esperado.Estrategia || resultado.Avaliacoes != esperado.Avaliacoes ||
+		!quaseIgual(resultado.PrecisaoK, esperado.PrecisaoK) || !quaseIgual(resultado.RecallK, esperado.RecallK) ||
+		!quaseIgual(resultado.Cobertura, esperado.Cobertura) || !quaseIgual(resultado.Diversidade, esperado.Diversidade) {
+		t.Errorf("resultado = %+v, esperado %+v", resultado, esperado)
+	}
+}
+
+func TestAcumuladorBacktestVazio(t *testing.T) {
+	resultado := (&acumuladorBacktest{recomendados: make(map[string]bool)}).resumir("legado", 0)
+	if resultado != (ResultadoBacktest{Estrategia: "legado"}) {
+		t.Errorf("resultado = %+v, esperado métricas zeradas", resultado)
+	}
+}
+
+func TestAplicarTransacao(t *testing.T) {
+	saldos := make(map[string]map[string]float64)
+	aplicarTransacao(saldos, dominio.Transacao{ClienteID: "c1", ProdutoID: "p1", Tipo: dominio.TipoTransacaoAplicacao, Valor: 1000})
+	aplicarTransacao(saldos, dominio.Transacao{ClienteID: "c1", ProdutoID: "p1", Tipo: dominio.TipoTransacaoResgate, Valor: 400})
+	aplicarTransacao(saldos, dominio.Transacao{ClienteID: "c1", ProdutoID: "p2", Tipo: "Transferencia", Valor: 500})
+
+	if saldo := saldos["c1"]["p1"]; !quaseIgual(saldo, 600) {
+		t.Errorf("saldo de p1 = %v, esperado 600", saldo)
+	}
+	if saldo := saldos["c1"]["p2"]; saldo != 0 {
+		t.Errorf("saldo de p2 = %v, esperado 0", saldo)
+	}
+}
+
+func TestAplicacoesNoPeriodo(t *testing.T) {
+	limite := agoraTeste.AddDate(0, 0, 30)
+	transacoes := []dominio.Transacao{
+		{ClienteID: "c1", ProdutoID: "p1", Tipo: dominio.TipoTransacaoAplicacao, Data: agoraTeste},
+		{ClienteID: "c1", ProdutoID: "p2", Tipo: dominio.TipoTransacaoResgate, Data: agoraTeste.AddDate(0, 0, 1)},
+		{ClienteID: "c2", ProdutoID: "p3", Tipo: dominio.TipoTransacaoAplicacao, Data: agoraTeste.AddDate(0, 0, 29)},
+		{ClienteID: "c1", ProdutoID: "p4", Tipo: dominio.TipoTransacaoAplicacao, Data: limite},
+	}
+
+	periodo := aplicacoesNoPeriodo(transacoes, limite)
+
+	if ids := slices.Sorted(maps.Keys(periodo["c1"])); !slices.Equal(ids, []string{"p1"}) {
+		t.Errorf("aplicações de c1 = %v, esperado [p1]", ids)
+	}
+	if ids := slices.Sorted(maps.Keys(periodo["c2"])); !slices.Equal(ids, []string{"p3"}) {
+		t.Errorf("aplicações de c2 = %v, esperado [p3]", ids)
+	}
+}
+
+func TestPosicoesDoSaldo(t *testing.T) {
+	catalogo := map[string]dominio.Produto{
+		"p1": {ID: "p1", Nome: "CDB 110%", TipoProduto: "CDB", RiscoAssociado: dominio.RiscoBaixo},
+		"p2": {ID: "p2", Nome: "Fundo XP", TipoProduto: "Fundo de Ações", RiscoAssociado: dominio.RiscoAlto},
+	}
+	posicoes := posicoesDoSaldo(map[string]float64{"p1": 600, "p2": 0, "p3": -10}, catalogo)
+
+	if len(posicoes) != 1 {
+		t.Fatalf("posições = %+v, esperado apenas p1", posicoes)
+	}
+	esperado := dominio.Posicao{ProdutoID: "p1", NomeProduto: "CDB 110%", TipoProduto: "CDB", RiscoAssociado: dominio.RiscoBaixo, Saldo: 600}
+	if posicoes[0] != esperado {
+		t.Errorf("posição = %+v, esperado %+v", posicoes[0], esperado)
+	}
+}
+
+func TestInteracoesNoPeriodo(t *testing.T) {
+	desde, ate := agoraTeste, agoraTeste.AddDate(0, 0, 10)
+	interacoes := []dominio.Interacao{
+		{ProdutoID: "antes", Data: desde.AddDate(0, 0, -1)},
+		{ProdutoID: "inicio", Data: desde},
+		{ProdutoID: "meio", Data: desde.AddDate(0, 0, 5)},
+		{ProdutoID: "fim", Data: ate},
+	}
+
+	var ids []string
+	for _, i := range interacoesNoPeriodo(interacoes, desde, ate) {
+		ids = append(ids, i.ProdutoID)
+	}
+	if !slices.Equal(ids, []string{"inicio", "meio"}) {
+		t.Errorf("interações = %v, esperado [inicio meio]", ids)
+	}
+}
+
+func TestHistoricoAte(t *testing.T) {
+	saldos := map[string]float64{"resgatado": 0, "carteira": 500}
+	interacoes := []dominio.Interacao{
+		{ProdutoID: "antiga", Data: agoraTeste.AddDate(-2, 0, 0)},
+		{ProdutoID: "futura", Data: agoraTeste.AddDate(0, 0, 1)},
+	}
+
+	aplicados, interagidos := historicoAte(saldos, interacoes, agoraTeste)
+
+	if ids := slices.Sorted(maps.Keys(aplicados)); !slices.Equal(ids, []string{"carteira", "resgatado"}) {
+		t.Errorf("aplicados = %v, esperado [carteira resgatado]", ids)
+	}
+	if ids := slices.Sorted(maps.Keys(interagidos)); !slices.Equal(ids, []string{"antiga"}) {
+		t.Errorf("interagidos = %v, esperado [antiga]", ids)
+	}
+}
@@ -0,0 +1,289 @@
+package casodeuso
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"backend/interno/dominio"
+)
+
+// ConfigPontuacao reúne os pesos e limiares usados pelas regras de scoring.
+// É carregada de um arquivo versionado e pode ser trocada em tempo de execução.
+type ConfigPontuacao struct {
+	Versao    string            `json:"versao"`
+	Pesos     PesosPontuacao    `json:"pesos"`
+	Limiares  LimiaresPontuacao `json:"limiares"`
+	Interesse ConfigInteresse   `json:"interesse"`
+	// Objetivos mapeia objetivo_investimento do cliente para horizonte e necessidade de liquidez
+	Objetivos map[string]ConfigObjetivo `json:"objetivos"`
+	// MatrizCompatibilidade define, de 0 a 1, quanto dos pontos de perfil cada nível de risco recebe
+	MatrizCompatibilidade map[dominio.PerfilRisco]map[dominio.NivelRisco]float64 `json:"matriz_compatibilidade"`
+	Alocacao              ConfigAlocacao                                         `json:"alocacao"`
+	Reranqueamento        ConfigReranqueamento                                   `json:"reranqueamento"`
+	// Experimento divide os clientes entre estratégias de scoring; vazio usa a estratégia padrão
+	Experimento ConfigExperimento `json:"experimento"`
+	Supressao   ConfigSupressao   `json:"supressao"`
+}
+
+// PesosPontuacao define quantos pontos cada regra soma (ou subtrai) ao produto
+type PesosPontuacao struct {
+	PerfilCompativel map[dominio.PerfilRisco]float64 `json:"perfil_compativel"` // por perfil de risco do cliente
+	Rentabilidade    float64                         `json:"rentabilidade"`
+	Acessibilidade   float64                         `json:"acessibilidade"`
+	Diversificacao   float64                         `json:"diversificacao"` // negativo para penalizar
+	Interesse        float64                         `json:"interesse"`
+	// RentabilidadeLiquida e Consistencia são os pontos máximos das regras contínuas
+	RentabilidadeLiquida float64 `json:"rentabilidade_liquida"`
+	Consistencia         float64 `json:"consistencia"`
+	Objetivo             float64 `json:"objetivo"`
+	Concentracao         float64 `json:"concentracao"` // bônus máximo por lacuna; o mesmo valor é a penalidade máxima
+	Supressao            float64 `json:"supressao"`    // negativo; aplicado a produtos descartados no modo "penalizar"
+}
+
+// LimiaresPontuacao define a partir de quando uma regra é aplicada
+type LimiaresPontuacao struct {
+	RentabilidadeMinima12m   float64 `json:"rentabilidade_minima_12m"`  // em % (ex: 10 = 10%)
+	AcessibilidadePatrimonio float64 `json:"acessibilidade_patrimonio"` // fração do patrimônio (ex: 0.05 = 5%)
+	// EscalaRentabilidadeLiquida é o retorno líquido (em %) que rende ~63% dos pontos da regra
+	EscalaRentabilidadeLiquida float64 `json:"escala_rentabilidade_liquida"`
+	// ConcentracaoMaxima é a fração da carteira em um tipo/risco a partir da qual há penalidade
+	ConcentracaoMaxima float64 `json:"concentracao_maxima"`
+}
+
+// ConfigInteresse define como as interações do cliente viram sinal de interesse.
+// Cada interação dentro da janela vale o peso do seu tipo, ajustado pela duração
+// e decaído pela idade (meia-vida); a soma é limitada a 1.
+type ConfigInteresse struct {
+	JanelaDias                int                `json:"janela_dias"`                 // interações mais antigas são ignoradas
+	MeiaVidaDias              float64            `json:"meia_vida_dias"`              // idade em que a interação vale metade
+	DuracaoReferenciaSegundos int                `json:"duracao_referencia_segundos"` // duração a partir da qual a interação vale integralmente
+	PesosTipo                 map[string]float64 `json:"pesos_tipo"`                  // tipos ausentes são ignorados
+}
+
+// ConfigObjetivo descreve o que um objetivo de investimento exige dos produtos
+type ConfigObjetivo struct {
+	HorizonteMeses     int      `json:"horizonte_meses"`      // quanto maior, mais a regra prefere liquidez longa dentro do prazo aceito
+	LiquidezMaximaDias int      `json:"liquidez_maxima_dias"` // prazo máximo de resgate aceitável (D+N)
+	TiposPreferidos    []string `json:"tipos_preferidos"`     // tipo_produto mais aderentes ao objetivo
+}
+
+// ConfigAlocacao define os limites usados na proposta de alocação
+type ConfigAlocacao struct {
+	FracaoPatrimonio float64 `json:"fracao_patrimonio"`  // parcela do patrimônio a distribuir (0 a 1)
+	MaximoPorProduto float64 `json:"maximo_por_produto"` // fração máxima do montante em um único produto
+	// LimitesRisco define, por perfil, a fração máxima do montante em cada classe de risco
+	LimitesRisco map[dominio.PerfilRisco]map[dominio.NivelRisco]float64 `json:"limites_risco"`
+}
+
+// ConfigReranqueamento controla a diversidade e o tamanho da lista final
+type ConfigReranqueamento struct {
+	Habilitado bool    `json:"habilitado"`
+	Lambda     float64 `json:"lambda"`     // 1 = apenas relevância, 0 = apenas diversidade
+	PesoTipo   float64 `json:"peso_tipo"`  // peso do mesmo tipo_produto na similaridade
+	PesoRisco  float64 `json:"peso_risco"` // peso do mesmo risco na similaridade
+	TopN       int     `json:"top_n"`      // 0 mantém todos os itens
+}
+
+// modos de tratamento de produtos descartados pelo cliente
+const (
+	ModoSupressaoPenalizar = "penalizar" // o produto continua na lista, com a penalidade de Pesos.Supressao
+	ModoSupressaoExcluir   = "excluir"   // o produto é barrado antes do scoring e aparece nas exclusões
+)
+
+// ConfigSupressao define o período de carência de produtos descartados
+type ConfigSupressao struct {
+	Dias int    `json:"dias"` // 0 desativa a supressão
+	Modo string `json:"modo"` // "penalizar" ou "excluir"
+}
+
+// ConfigExperimento define o teste A/B entre estratégias de scoring
+type ConfigExperimento struct {
+	Nome      string                `json:"nome"` // entra no hash; trocar o nome redistribui os clientes
+	Variantes []VarianteExperimento `json:"variantes"`
+}
+
+// VarianteExperimento associa um grupo do experimento a uma estratégia registrada
+type VarianteExperimento struct {
+	Nome       string  `json:"nome"`
+	Estrategia string  `json:"estrategia"`
+	Trafego    float64 `json:"trafego"` // fração dos clientes (a soma das variantes deve ser 1)
+}
+
+// ObjetivoDoCliente localiza a configuração do objetivo ignorando diferenças de caixa
+func (c ConfigPontuacao) ObjetivoDoCliente(objetivo string) (ConfigObjetivo, bool) {
+	if cfg, ok := c.Objetivos[objetivo]; ok {
+		return cfg, true
+	}
+	for nome, cfg := range c.Objetivos {
+		if strings.EqualFold(nome, strings.TrimSpace(objetivo)) {
+			return cfg, true
+		}
+	}
+	return ConfigObjetivo{}, false
+}
+
+// ConfigPadrao retorna os valores originais do projeto, usados quando nenhum arquivo é informado
+func ConfigPadrao() ConfigPontuacao {
+	return ConfigPontuacao{
+		Versao: "padrao",
+		Pesos: PesosPontuacao{
+			PerfilCompativel: map[dominio.PerfilRisco]float64{
+				dominio.PerfilConservador: 0.3,
+				dominio.PerfilModerado:    0.25,
+				dominio.PerfilArrojado:    0.2,
+			},
+			Rentabilidade:  0.1,
+			Acessibilidade: 0.1,
+			Diversificacao: -0.2,
+			Interesse:      0.15,
+
+			RentabilidadeLiquida: 0.1,
+			Consistencia:         0.05,
+			Objetivo:             0.15,
+			Concentracao:         0.15,
+			Supressao:            -0.5,
+		},
+		Limiares: LimiaresPontuacao{
+			RentabilidadeMinima12m:   10.0,
+			AcessibilidadePatrimonio: 0.05,
+
+			EscalaRentabilidadeLiquida: 10.0,
+			ConcentracaoMaxima:         0.4,
+		},
+		Interesse: ConfigInteresse{
+			JanelaDias:                180,
+			MeiaVidaDias:              30,
+			DuracaoReferenciaSegundos: 120,
+			PesosTipo: map[string]float64{
+				"Visualizacao": 0.3,
+				"Clique":       0.5,
+				"Favorito":     0.8,
+				"Simulacao":    1.0,
+			},
+		},
+		MatrizCompatibilidade: map[dominio.PerfilRisco]map[dominio.NivelRisco]float64{
+			dominio.PerfilConservador: {dominio.RiscoBaixo: 1, dominio.RiscoMedio: 0.3, dominio.RiscoAlto: 0},
+			dominio.PerfilModerado:    {dominio.RiscoBaixo: 0.5, dominio.RiscoMedio: 1, dominio.RiscoAlto: 0.3},
+			dominio.PerfilArrojado:    {dominio.RiscoBaixo: 0.2, dominio.RiscoMedio: 0.6, dominio.RiscoAlto: 1},
+		},
+		Alocacao: ConfigAlocacao{
+			FracaoPatrimonio: 1.0,
+			MaximoPorProduto: 0.4,
+			LimitesRisco: map[dominio.PerfilRisco]map[dominio.NivelRisco]float64{
+				dominio.PerfilConservador: {dominio.RiscoBaixo: 1.0},
+				dominio.PerfilModerado:    {dominio.RiscoBaixo: 1.0, dominio.RiscoMedio: 0.6},
+				dominio.PerfilArrojado:    {dominio.RiscoBaixo: 1.0, dominio.RiscoMedio: 0.8, dominio.RiscoAlto: 0.6},
+			},
+		},
+		Reranqueamento: ConfigReranqueamento{
+			Habilitado: true,
+			Lambda:     0.7,
+			PesoTipo:   0.7,
+			PesoRisco:  0.3,
+			TopN:       0,
+		},
+		Supressao: ConfigSupressao{
+			Dias: 30,
+			Modo: ModoSupressaoPenalizar,
+		},
+		Objetivos: map[string]ConfigObjetivo{
+			"Reserva de Emergência":    {HorizonteMeses: 6, LiquidezMaximaDias: 0, TiposPreferidos: []string{"CDB", "Fundo de Renda Fixa"}},
+			"Viagem":                   {HorizonteMeses: 12, LiquidezMaximaDias: 1, TiposPreferidos: []string{"CDB", "Fundo de Renda Fixa"}},
+			"Compra de Imóvel":         {HorizonteMeses: 36, LiquidezMaximaDias: 30, TiposPreferidos: []string{"LCI/LCA", "CDB", "Fundo de Renda Fixa"}},
+			"Educação":                 {HorizonteMeses: 60, LiquidezMaximaDias: 30, TiposPreferidos: []string{"Fundo de Renda Fixa", "LCI/LCA", "Fundo Multimercado"}},
+			"Crescimento de Capital":   {HorizonteMeses: 120, LiquidezMaximaDias: 60, TiposPreferidos: []string{"Fundo de Ações", "Fundo Multimercado"}},
+			"Independência Financeira": {HorizonteMeses: 180, LiquidezMaximaDias: 60, TiposPreferidos: []string{"Fundo de Ações", "Previdência", "Fundo Multimercado"}},
+			"Aposentadoria":            {HorizonteMeses: 240, LiquidezMaximaDias: 60, TiposPreferidos: []string{"Previdência", "Fundo de Ações", "Fundo Multimercado"}},
+		},
+	}
+}
+
+// Validar garante que a configuração pode ser usada com segurança pelas regras
+func (c ConfigPontuacao) Validar() error {
+	if c.Versao == "" {
+		return errors.New("versão da configuração não informada")
+	}
+	for _, perfil := range []dominio.PerfilRisco{dominio.PerfilConservador, dominio.PerfilModerado, dominio.PerfilArrojado} {
+		if _, ok := c.Pesos.PerfilCompativel[perfil]; !ok {
+			return fmt.Errorf("peso de perfil compatível não informado para %s", perfil)
+		}
+	}
+	if len(c.Interesse.PesosTipo) == 0 {
+		return errors.New("pesos por tipo de interação não informados")
+	}
+	if c.Limiares.AcessibilidadePatrimonio < 0 || c.Limiares.AcessibilidadePatrimonio > 1 {
+		return fmt.Errorf("limiar de acessibilidade deve estar entre 0 e 1: %v", c.Limiares.AcessibilidadePatrimonio)
+	}
+	for perfil, niveis := range c.MatrizCompatibilidade {
+		for nivel, fator := range niveis {
+			if fator < 0 || fator > 1 {
+				return fmt.Errorf("fator de compatibilidade %s/%s deve estar entre 0 e 1: %v", perfil, nivel, fator)
+			}
+		}
+	}
+	if c.Limiares.ConcentracaoMaxima <= 0 || c.Limiares.ConcentracaoMaxima >= 1 {
+		return fmt.Errorf("limiar de concentração deve estar entre 0 e 1 (exclusivo): %v", c.Limiares.ConcentracaoMaxima)
+	}
+	if c.Alocacao.FracaoPatrimonio <= 0 || c.Alocacao.FracaoPatrimonio > 1 {
+		return fmt.Errorf("fração do patrimônio para alocação deve estar entre 0 e 1: %v", c.Alocacao.FracaoPatrimonio)
+	}
+	if c.Alocacao.MaximoPorProduto <= 0 || c.Alocacao.MaximoPorProduto > 1 {
+		return fmt.Errorf("máximo por produto na alocação deve estar entre 0 e 1: %v", c.Alocacao.MaximoPorProduto)
+	}
+	for nome, objetivo := range c.Objetivos {
+		if objetivo.HorizonteMeses < 0 || objetivo.LiquidezMaximaDias < 0 {
+			return fmt.Errorf("horizonte e liquidez máxima do objetivo %s não podem ser negativos", nome)
+		}
+	}
+	if c.Reranqueamento.Lambda < 0 || c.Reranqueamento.Lambda > 1 {
+		return fmt.Errorf("lambda do re-ranqueamento deve estar entre 0 e 1: %v", c.Reranqueamento.Lambda)
+	}
+	if c.Reranqueamento.TopN < 0 {
+		return errors.New("top_n do re-ranqueamento não pode ser negativo")
+	}
+	if c.Limiares.EscalaRentabilidadeLiquida <= 0 {
+		return errors.New("escala de rentabilidade líquida deve ser positiva")
+	}
+	if c.Interesse.JanelaDias <= 0 || c.Interesse.MeiaVidaDias <= 0 {
+		return errors.New("janela e meia-vida de interesse devem ser positivas")
+	}
+	if c.Supressao.Dias < 0 {
+		return errors.New("dias de supressão não podem ser negativos")
+	}
+	if c.Supressao.Dias > 0 && c.Supressao.Modo != ModoSupressaoPenalizar && c.Supressao.Modo != ModoSupressaoExcluir {
+		return fmt.Errorf("modo de supressão inválido: %q", c.Supressao.Modo)
+	}
+	return c.Experimento.Validar()
+}
+
+// Validar garante que as variantes têm nomes únicos e que as frações cobrem todo o tráfego
+func (e ConfigExperimento) Validar() error {
+	if len(e.Variantes) == 0 {
+		return nil
+	}
+	if e.Nome == "" {
+		return errors.New("nome do experimento não informado")
+	}
+
+	nomes := make(map[string]bool, len(e.Variantes))
+	total := 0.0
+	for _, v := range e.Variantes {
+		if v.Nome == "" || v.Estrategia == "" {
+			return errors.New("variante do experimento sem nome ou estratégia")
+		}
+		if nomes[v.Nome] {
+			return fmt.Errorf("variante do experimento duplicada: %s", v.Nome)
+		}
+		nomes[v.Nome] = true
+		if v.Trafego < 0 || v.Trafego > 1 {
+			return fmt.Errorf("tráfego da variante %s deve estar entre 0 e 1: %v", v.Nome, v.Trafego)
+		}
+		total += v.Trafego
+	}
+	if math.Abs(total-1) > 1e-6 {
+		return fmt.Errorf("a soma do tráfego das variantes deve ser 1: %v", total)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package casodeuso
+
+import (
+	"testing"
+
+	"backend/interno/dominio"
+)
+
+func TestConfigPadraoValida(t *testing.T) {
+	if err := ConfigPadrao().Validar(); err != nil {
+		t.Fatalf("configuração padrão inválida: %v", err)
+	}
+}
+
+func TestConfigPontuacaoValidar(t *testing.T) {
+	casos := []struct {
+		nome    string
+		ajustar func(cfg *ConfigPontuacao)
+	}{
+		{"sem versão", func(cfg *ConfigPontuacao) { cfg.Versao = "" }},
+		{"sem peso de perfil", func(cfg *ConfigPontuacao) { delete(cfg.Pesos.PerfilCompativel, dominio.PerfilArrojado) }},
+		{"sem pesos por tipo de interação", func(cfg *ConfigPontuacao) { cfg.Interesse.PesosTipo = nil }},
+		{"acessibilidade acima de 1", func(cfg *ConfigPontuacao) { cfg.Limiares.AcessibilidadePatrimonio = 1.5 }},
+		{"fator da matriz acima de 1", func(cfg *ConfigPontuacao) {
+			cfg.MatrizCompatibilidade[dominio.PerfilModerado][dominio.RiscoAlto] = 1.2
+		}},
+		{"concentração zero", func(cfg *ConfigPontuacao) { cfg.Limiares.ConcentracaoMaxima = 0 }},
+		{"concentração um", func(cfg *ConfigPontuacao) { cfg.Limiares.ConcentracaoMaxima = 1 }},
+		{"fração do patrimônio zero", func(cfg *ConfigPontuacao) { cfg.Alocacao.FracaoPatrimonio = 0 }},
+		{"máximo por produto acima de 1", func(cfg *ConfigPontuacao) { cfg.Alocacao.MaximoPorProduto = 1.1 }},
+		{"horizonte negativo", func(cfg *ConfigPontuacao) {
+			cfg.Objetivos["Viagem"] = ConfigObjetivo{HorizonteMeses: -1}
+		}},
+		{"liquidez negativa", func(cfg *ConfigPontuacao) {
+			cfg.Objetivos["Viagem"] = ConfigObjetivo{HorizonteMeses: 12, LiquidezMaximaDias: -1}
+		}},
+		{"lambda acima de 1", func(cfg *ConfigPontuacao) { cfg.Reranqueamento.Lambda = 1.5 }},
+		{"top-N negativo", func(cfg *ConfigPontuacao) { cfg.Reranqueamento.TopN = -1 }},
+		{"escala de rentabilidade zero", func(cfg *ConfigPontuacao) { cfg.Limiares.EscalaRentabilidadeLiquida = 0 }},
+		{"janela de interesse zero", func(cfg *ConfigPontuacao) { cfg.Interesse.JanelaDias = 0 }},
+		{"meia-vida zero", func(cfg *ConfigPontuacao) { cfg.Interesse.MeiaVidaDias = 0 }},
+		{"dias de supressão negativos", func(cfg *ConfigPontuacao) { cfg.Supressao.Dias = -1 }},
+		{"modo de supressão inválido", func(cfg *ConfigPontuacao) { cfg.Supressao.Modo = "ocultar" }},
+		{"experimento inválido", func(cfg *ConfigPontuacao) {
+			cfg.Experimento = ConfigExperimento{Variantes: []VarianteExperimento{{Nome: "a", Estrategia: "padrao", Trafego: 1}}}
+		}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			cfg := ConfigPadrao()
+			c.ajustar(&cfg)
+			if err := cfg.Validar(); err == nil {
+				t.Error("configuração deveria ser rejeitada")
+			}
+		})
+	}
+}
+
+func TestConfigExperimentoValidar(t *testing.T) {
+	casos := []struct {
+		nome        string
+		experimento ConfigExperimento
+		valido      bool
+	}{
+		{"sem variantes", ConfigExperimento{}, true},
+		{"divisão completa", ConfigExperimento{Nome: "exp", Variantes: []VarianteExperimento{
+			{Nome: "controle", Estrategia: EstrategiaLegado, Trafego: 0.5},
+			{Nome: "tratamento", Estrategia: EstrategiaPadrao, Trafego: 0.5},
+		}}, true},
+		{"sem nome", ConfigExperimento{Variantes: []VarianteExperimento{
+			{Nome: "a", Estrategia: EstrategiaPadrao, Trafego: 1},
+		}}, false},
+		{"variante sem estratégia", ConfigExperimento{Nome: "exp", Variantes: []VarianteExperimento{
+			{Nome: "a", Trafego: 1},
+		}}, false},
+		{"variante duplicada", ConfigExperimento{Nome: "exp", Variantes: []VarianteExperimento{
+			{Nome: "a", Estrategia: EstrategiaPadrao, Trafego: 0.5},
+			{Nome: "a", Estrategia: EstrategiaLegado, Trafego: 0.5},
+		}}, false},
+		{"tráfego fora do intervalo", ConfigExperimento{Nome: "exp", Variantes: []VarianteExperimento{
+			{Nome: "a", Estrategia: EstrategiaPadrao, Trafego: 1.5},
+			{Nome: "b", Estrategia: EstrategiaLegado, Trafego: -0.5},
+		}}, false},
+		{"soma diferente de 1", ConfigExperimento{Nome: "exp", Variantes: []VarianteExperimento{
+			{Nome: "a", Estrategia: EstrategiaPadrao, Trafego: 0.5},
+			{Nome: "b", Estrategia: EstrategiaLegado, Trafego: 0.3},
+		}}, false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if err := c.experimento.Validar(); (err == nil) != c.valido {
+				t.Errorf("válido = %v, esperado %v (erro: %v)", err == nil, c.valido, err)
+			}
+		})
+	}
+}
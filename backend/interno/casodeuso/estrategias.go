@@ -0,0 +1,99 @@
+package casodeuso
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+const (
+	// EstrategiaPadrao é usada quando não há experimento configurado
+	EstrategiaPadrao = "padrao"
+	// EstrategiaLegado mantém apenas as regras originais do projeto, útil como grupo de controle
+	EstrategiaLegado = "legado"
+
+	// baldesExperimento é a resolução da divisão de tráfego (0,01%)
+	baldesExperimento = 10000
+)
+
+// RegistroEstrategias mantém as estratégias de scoring nomeadas, cada uma com seu próprio registro de regras
+type RegistroEstrategias struct {
+	mu          sync.RWMutex
+	estrategias map[string]*RegistroRegras
+}
+
+func NovoRegistroEstrategias() *RegistroEstrategias {
+	return &RegistroEstrategias{estrategias: make(map[string]*RegistroRegras)}
+}
+
+// Registrar adiciona uma estratégia com o conjunto de regras informado
+func (r *RegistroEstrategias) Registrar(nome string, regras *RegistroRegras) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, existe := r.estrategias[nome]; existe {
+		return fmt.Errorf("estratégia já registrada: %s", nome)
+	}
+	r.estrategias[nome] = regras
+	return nil
+}
+
+// Obter retorna o registro de regras da estratégia
+func (r *RegistroEstrategias) Obter(nome string) (*RegistroRegras, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	regras, existe := r.estrategias[nome]
+	return regras, existe
+}
+
+// Nomes lista as estratégias registradas em ordem alfabética
+func (r *RegistroEstrategias) Nomes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nomes := make([]string, 0, len(r.estrategias))
+	for nome := range r.estrategias {
+		nomes = append(nomes, nome)
+	}
+	sort.Strings(nomes)
+	return nomes
+}
+
+// EstrategiasPadrao retorna as estratégias disponíveis no projeto
+func EstrategiasPadrao() *RegistroEstrategias {
+	registro := NovoRegistroEstrategias()
+	for nome, regras := range map[string]*RegistroRegras{
+		EstrategiaPadrao: RegistroPadrao(),
+		EstrategiaLegado: RegistroLegado(),
+	} {
+		if err := registro.Registrar(nome, regras); err != nil {
+			panic(err)
+		}
+	}
+	return registro
+}
+
+// SortearVariante escolhe a variante do cliente de forma determinística: o mesmo cliente
+// cai sempre na mesma variante enquanto o nome do experimento e as frações não mudarem.
+func SortearVariante(clienteID string, experimento ConfigExperimento) VarianteExperimento {
+	if len(experimento.Variantes) == 0 {
+		return VarianteExperimento{Nome: EstrategiaPadrao, Estrategia: EstrategiaPadrao, Trafego: 1}
+	}
+
+	// o nome do experimento entra no hash para que experimentos diferentes não repitam a mesma divisão
+	h := fnv.New64a()
+	h.Write([]byte(experimento.Nome + ":" + clienteID))
+	posicao := float64(h.Sum64()%baldesExperimento) / baldesExperimento
+
+	acumulado := 0.0
+	for _, variante := range experimento.Variantes {
+		acumulado += variante.Trafego
+		if posicao < acumulado {
+			return variante
+		}
+	}
+	// arredondamentos na soma das frações caem na última variante
+	return experimento.Variantes[len(experimento.Variantes)-1]
+}
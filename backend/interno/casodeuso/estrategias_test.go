@@ -0,0 +1,98 @@
+package casodeuso
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+func experimentoMeioAMeio(nome string) ConfigExperimento {
+	return ConfigExperimento{Nome: nome, Variantes: []VarianteExperimento{
+		{Nome: "controle", Estrategia: EstrategiaLegado, Trafego: 0.5},
+		{Nome: "tratamento", Estrategia: EstrategiaPadrao, Trafego: 0.5},
+	}}
+}
+
+func TestSortearVarianteSemExperimento(t *testing.T) {
+	variante := SortearVariante("cliente-1", ConfigExperimento{})
+	if variante.Estrategia != EstrategiaPadrao || variante.Trafego != 1 {
+		t.Errorf("variante = %+v, esperado a estratégia padrão com todo o tráfego", variante)
+	}
+}
+
+func TestSortearVarianteDeterministica(t *testing.T) {
+	experimento := experimentoMeioAMeio("exp")
+	for i := range 100 {
+		id := fmt.Sprintf("cliente-%d", i)
+		primeira := SortearVariante(id, experimento)
+		for range 5 {
+			if outra := SortearVariante(id, experimento); outra != primeira {
+				t.Fatalf("cliente %s mudou de %s para %s", id, primeira.Nome, outra.Nome)
+			}
+		}
+	}
+}
+
+func TestSortearVarianteDistribuicao(t *testing.T) {
+	const total = 10000
+	casos := []struct {
+		nome      string
+		trafegoA  float64
+		esperadoA float64
+	}{
+		{"meio a meio", 0.5, 0.5},
+		{"noventa por dez", 0.9, 0.9},
+		{"todo o tráfego em uma variante", 1, 1},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			experimento := ConfigExperimento{Nome: "exp", Variantes: []VarianteExperimento{
+				{Nome: "a", Estrategia: EstrategiaPadrao, Trafego: c.trafegoA},
+				{Nome: "b", Estrategia: EstrategiaLegado, Trafego: 1 - c.trafegoA},
+			}}
+			contagemA := 0
+			for i := range total {
+				if SortearVariante(fmt.Sprintf("cliente-%d", i), experimento).Nome == "a" {
+					contagemA++
+				}
+			}
+			if fracao := float64(contagemA) / total; math.Abs(fracao-c.esperadoA) > 0.03 {
+				t.Errorf("fração na variante a = %v, esperado perto de %v", fracao, c.esperadoA)
+			}
+		})
+	}
+}
+
+func TestSortearVarianteNomeRedistribui(t *testing.T) {
+	primeiro, segundo := experimentoMeioAMeio("exp-1"), experimentoMeioAMeio("exp-2")
+	mudaram := 0
+	for i := range 1000 {
+		id := fmt.Sprintf("cliente-%d", i)
+		if SortearVariante(id, primeiro).Nome != SortearVariante(id, segundo).Nome {
+			mudaram++
+		}
+	}
+	// com hashes independentes, cerca de metade dos clientes troca de grupo
+	if mudaram < 300 || mudaram > 700 {
+		t.Errorf("%d de 1000 clientes mudaram de variante, esperado perto de 500", mudaram)
+	}
+}
+
+func TestRegistroEstrategias(t *testing.T) {
+	registro := EstrategiasPadrao()
+
+	if nomes := registro.Nomes(); !slices.Equal(nomes, []string{EstrategiaLegado, EstrategiaPadrao}) {
+		t.Errorf("nomes = %v", nomes)
+	}
+	if _, existe := registro.Obter(EstrategiaPadrao); !existe {
+		t.Error("estratégia padrão não encontrada")
+	}
+	if _, existe := registro.Obter("inexistente"); existe {
+		t.Error("estratégia inexistente encontrada")
+	}
+	if err := registro.Registrar(EstrategiaPadrao, RegistroPadrao()); err == nil {
+		t.Error("registrar estratégia repetida deveria falhar")
+	}
+}
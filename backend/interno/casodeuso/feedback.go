@@ -0,0 +1,67 @@
+package casodeuso
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// RegistrarFeedback valida e grava os eventos de feedback de uma recomendação persistida.
+// Cada evento precisa referenciar um produto que fez parte da recomendação.
+func (s *ServicoRecomendacao) RegistrarFeedback(recomendacaoID string, eventos []dominio.EventoFeedback) error {
+	recomendacao, err := s.repo.BuscarRecomendacao(recomendacaoID)
+	if err != nil {
+		return err
+	}
+
+	// regras que pontuaram cada item, para que as métricas por regra não dependam do JSON da recomendação
+	regrasPorProduto := make(map[string][]string, len(recomendacao.Recomendacoes))
+	for _, item := range recomendacao.Recomendacoes {
+		regras := make([]string, 0, len(item.Explicacao))
+		for _, contribuicao := range item.Explicacao {
+			regras = append(regras, contribuicao.RegraID)
+		}
+		regrasPorProduto[item.Produto.ID] = regras
+	}
+
+	agora := time.Now()
+	for i := range eventos {
+		tipo, err := dominio.ParseTipoFeedback(string(eventos[i].Tipo))
+		if err != nil {
+			return fmt.Errorf("%w: %v", dominio.ErrFeedbackInvalido, err)
+		}
+		regras, existe := regrasPorProduto[eventos[i].ProdutoID]
+		if !existe {
+			return fmt.Errorf("%w: produto %s não faz parte da recomendação", dominio.ErrFeedbackInvalido, eventos[i].ProdutoID)
+		}
+		eventos[i].RecomendacaoID = recomendacao.ID
+		eventos[i].Tipo = tipo
+		eventos[i].Data = agora
+		eventos[i].Regras = regras
+	}
+
+	if err := s.repo.SalvarFeedback(eventos); err != nil {
+		slog.Error("Erro ao salvar feedback", "erro", err, "id_recomendacao", recomendacaoID)
+		return err
+	}
+
+	// o feedback já foi gravado; uma falha aqui não deve fazer o cliente reenviar os eventos
+	if err := s.suprimirDescartados(recomendacao, eventos); err != nil {
+		slog.Error("Erro ao registrar supressão de produtos descartados", "erro", err, "id_recomendacao", recomendacaoID)
+	}
+
+	slog.Info("Feedback registrado", "id_recomendacao", recomendacaoID, "cliente_id", recomendacao.ClienteID, "eventos", len(eventos))
+	return nil
+}
+
+// MetricasFeedback retorna as taxas de aceitação por produto e por regra desde a data informada
+func (s *ServicoRecomendacao) MetricasFeedback(desde time.Time) (*dominio.MetricasFeedback, error) {
+	metricas, err := s.repo.AgregarFeedback(desde)
+	if err != nil {
+		slog.Error("Erro ao agregar feedback", "erro", err)
+		return nil, err
+	}
+	return metricas, nil
+}
@@ -0,0 +1,72 @@
+package casodeuso
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"backend/interno/dominio"
+)
+
+// recomendacaoComExplicacao tem p1 pontuado por perfil e rentabilidade e p2 só por perfil
+func recomendacaoComExplicacao() dominio.ResultadoRecomendacao {
+	p1 := itemAlocacao("p1", dominio.RiscoMedio, 0.35, 0)
+	p1.Explicacao = []dominio.ContribuicaoRegra{{RegraID: "perfil"}, {RegraID: "rentabilidade"}}
+	p2 := itemAlocacao("p2", dominio.RiscoMedio, 0.25, 0)
+	p2.Explicacao = []dominio.ContribuicaoRegra{{RegraID: "perfil"}}
+	return dominio.ResultadoRecomendacao{ID: "r1", ClienteID: "c1", Recomendacoes: []dominio.RecomendacaoItem{p1, p2}}
+}
+
+func TestRegistrarFeedback(t *testing.T) {
+	repo := novoRepositorioFake(moderado)
+	repo.recomendacoes = []dominio.ResultadoRecomendacao{recomendacaoComExplicacao()}
+	servico := NovoServicoRecomendacao(repo, nil)
+
+	err := servico.RegistrarFeedback("r1", []dominio.EventoFeedback{
+		{ProdutoID: "p1", Tipo: " Aceito "},
+		{ProdutoID: "p2", Tipo: dominio.FeedbackVisualizado},
+	})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if len(repo.feedback) != 2 {
+		t.Fatalf("eventos gravados = %+v, esperado 2", repo.feedback)
+	}
+	aceito := repo.feedback[0]
+	if aceito.RecomendacaoID != "r1" || aceito.Tipo != dominio.FeedbackAceito || aceito.Data.IsZero() {
+		t.Errorf("evento = %+v, esperado aceito vinculado a r1 e com data", aceito)
+	}
+	if !slices.Equal(aceito.Regras, []string{"perfil", "rentabilidade"}) {
+		t.Errorf("regras = %v, esperado as regras que pontuaram p1", aceito.Regras)
+	}
+}
+
+func TestRegistrarFeedbackInvalido(t *testing.T) {
+	casos := []struct {
+		nome           string
+		recomendacaoID string
+		evento         dominio.EventoFeedback
+		erro           error
+	}{
+		{"recomendação inexistente", "r9", dominio.EventoFeedback{ProdutoID: "p1", Tipo: dominio.FeedbackAceito}, dominio.ErrRecomendacaoNaoEncontrada},
+		{"tipo desconhecido", "r1", dominio.EventoFeedback{ProdutoID: "p1", Tipo: "comprado"}, dominio.ErrFeedbackInvalido},
+		{"produto fora da recomendação", "r1", dominio.EventoFeedback{ProdutoID: "p9", Tipo: dominio.FeedbackAceito}, dominio.ErrFeedbackInvalido},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			repo := novoRepositorioFake(moderado)
+			repo.recomendacoes = []dominio.ResultadoRecomendacao{recomendacaoComExplicacao()}
+			servico := NovoServicoRecomendacao(repo, nil)
+
+			err := servico.RegistrarFeedback(c.recomendacaoID, []dominio.EventoFeedback{c.evento})
+			if !errors.Is(err, c.erro) {
+				t.Errorf("erro = %v, esperado %v", err, c.erro)
+			}
+			if len(repo.feedback) != 0 {
+				t.Errorf("eventos gravados = %+v, esperado nenhum", repo.feedback)
+			}
+		})
+	}
+}
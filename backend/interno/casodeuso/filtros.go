@@ -0,0 +1,88 @@
+package casodeuso
+
+import (
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// FiltroElegibilidade decide, antes do scoring, se um produto pode ser oferecido ao cliente.
+// Diferente das regras, filtros não somam pontos: o produto barrado nem chega a ser pontuado.
+type FiltroElegibilidade interface {
+	ID() string
+	// Avaliar retorna nil quando o produto é elegível, ou a exclusão com o motivo
+	Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) *dominio.ExclusaoProduto
+}
+
+// filtrosObrigatorios são exigências regulatórias e por isso não ficam no registro de regras,
+// onde poderiam ser desabilitadas
+func filtrosObrigatorios() []FiltroElegibilidade {
+	return []FiltroElegibilidade{FiltroSuitability{}}
+}
+
+// filtrosPadrao acrescenta aos obrigatórios os filtros que dependem da configuração
+func filtrosPadrao() []FiltroElegibilidade {
+	return append(filtrosObrigatorios(), FiltroSupressao{})
+}
+
+// FiltroSuitability aplica a verificação de adequação (Resolução CVM nº 30): o produto não
+// pode ter risco acima do tolerado pelo perfil do cliente. Clientes sem perfil válido e
+// produtos sem classificação de risco não recebem recomendação.
+type FiltroSuitability struct{}
+
+func (FiltroSuitability) ID() string { return "suitability" }
+
+func (f FiltroSuitability) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) *dominio.ExclusaoProduto {
+	perfil := ctx.Cliente.PerfilRisco
+	if !perfil.Valido() {
+		return f.excluir(prod, "perfil de risco do cliente inválido ou não avaliado", perfil)
+	}
+
+	if !prod.RiscoAssociado.Valido() {
+		return f.excluir(prod, "produto sem classificação de risco válida", perfil)
+	}
+
+	if prod.RiscoAssociado > perfil.RiscoMaximo() {
+		return f.excluir(prod, "risco do produto acima do permitido para o perfil do cliente", perfil)
+	}
+	return nil
+}
+
+func (f FiltroSuitability) excluir(prod dominio.Produto, motivo string, perfil dominio.PerfilRisco) *dominio.ExclusaoProduto {
+	return &dominio.ExclusaoProduto{
+		ProdutoID:   prod.ID,
+		NomeProduto: prod.Nome,
+		FiltroID:    f.ID(),
+		Motivo:      motivo,
+		Entradas: map[string]interface{}{
+			"perfil_risco":    perfil.String(),
+			"risco_associado": prod.RiscoAssociado.String(),
+		},
+	}
+}
+
+// FiltroSupressao barra produtos descartados pelo cliente enquanto durar a carência,
+// quando a configuração usa o modo "excluir"
+type FiltroSupressao struct{}
+
+func (FiltroSupressao) ID() string { return "supressao" }
+
+func (f FiltroSupressao) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) *dominio.ExclusaoProduto {
+	if ctx.Config.Supressao.Modo != ModoSupressaoExcluir {
+		return nil
+	}
+	supressao, ok := ctx.Supressoes[prod.ID]
+	if !ok {
+		return nil
+	}
+	return &dominio.ExclusaoProduto{
+		ProdutoID:   prod.ID,
+		NomeProduto: prod.Nome,
+		FiltroID:    f.ID(),
+		Motivo:      "produto descartado pelo cliente recentemente",
+		Entradas: map[string]interface{}{
+			"descartado_em": supressao.Inicio.Format(time.DateOnly),
+			"expira_em":     supressao.ExpiraEm.Format(time.DateOnly),
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package casodeuso
+
+import (
+	"testing"
+
+	"backend/interno/dominio"
+)
+
+func TestFiltroSuitability(t *testing.T) {
+	casos := []struct {
+		nome     string
+		perfil   dominio.PerfilRisco
+		risco    dominio.NivelRisco
+		excluido bool
+	}{
+		{"conservador e risco baixo", dominio.PerfilConservador, dominio.RiscoBaixo, false},
+		{"conservador e risco médio", dominio.PerfilConservador, dominio.RiscoMedio, true},
+		{"moderado e risco médio", dominio.PerfilModerado, dominio.RiscoMedio, false},
+		{"moderado e risco alto", dominio.PerfilModerado, dominio.RiscoAlto, true},
+		{"arrojado e risco alto", dominio.PerfilArrojado, dominio.RiscoAlto, false},
+		{"perfil indefinido", dominio.PerfilIndefinido, dominio.RiscoBaixo, true},
+		{"produto sem risco", dominio.PerfilArrojado, dominio.RiscoIndefinido, true},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			ctx := &ContextoPontuacao{Cliente: &dominio.Cliente{PerfilRisco: c.perfil}, Config: ConfigPadrao()}
+			exclusao := FiltroSuitability{}.Avaliar(ctx, dominio.Produto{ID: "p1", RiscoAssociado: c.risco})
+
+			if (exclusao != nil) != c.excluido {
+				t.Fatalf("excluído = %v, esperado %v", exclusao != nil, c.excluido)
+			}
+			if exclusao != nil && (exclusao.FiltroID != "suitability" || exclusao.ProdutoID != "p1" || exclusao.Motivo == "") {
+				t.Errorf("exclusão incompleta: %+v", exclusao)
+			}
+		})
+	}
+}
+
+func TestFiltroSupressao(t *testing.T) {
+	supressoes := map[string]dominio.Supressao{"p1": {ProdutoID: "p1", Inicio: agoraTeste, ExpiraEm: agoraTeste.AddDate(0, 0, 30)}}
+
+	casos := []struct {
+		nome     string
+		modo     string
+		produto  string
+		excluido bool
+	}{
+		{"modo excluir barra o produto suprimido", ModoSupressaoExcluir, "p1", true},
+		{"modo excluir mantém os demais", ModoSupressaoExcluir, "p2", false},
+		{"modo penalizar deixa para a regra", ModoSupressaoPenalizar, "p1", false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			cfg := ConfigPadrao()
+			cfg.Supressao.Modo = c.modo
+			ctx := &ContextoPontuacao{Cliente: &moderado, Config: cfg, Supressoes: supressoes}
+
+			if exclusao := (FiltroSupressao{}).Avaliar(ctx, dominio.Produto{ID: c.produto}); (exclusao != nil) != c.excluido {
+				t.Errorf("excluído = %v, esperado %v", exclusao != nil, c.excluido)
+			}
+		})
+	}
+}
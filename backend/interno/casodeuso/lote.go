@@ -0,0 +1,258 @@
+package casodeuso
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+const (
+	// tamanhoPaginaClientes é quantos clientes ficam em memória por vez na publicação; também é
+	// o intervalo de checkpoint, ou seja, o máximo de mensagens republicadas após um reinício
+	tamanhoPaginaClientes = 500
+	// reservaPublicacaoLote é por quanto tempo a instância que publica o lote o mantém reservado.
+	// É renovada a cada página; se expirar, outra instância (ou a mesma, ao reiniciar) retoma o lote.
+	reservaPublicacaoLote = 2 * time.Minute
+	// tentativasPaginaLote é quantas vezes a leitura de uma página é tentada antes de a
+	// publicação ser abandonada e deixada para a próxima retomada
+	tentativasPaginaLote = 3
+)
+
+// reservaPublicacao garante que a reserva cubra ao menos duas páginas no ritmo limitado,
+// para que uma taxa baixa não faça outra instância assumir um lote ainda em publicação
+func (s *ServicoRecomendacao) reservaPublicacao() time.Duration {
+	if s.taxaPublicacaoMassa <= 0 {
+		return reservaPublicacaoLote
+	}
+	porPagina := time.Duration(tamanhoPaginaClientes) * time.Second / time.Duration(s.taxaPublicacaoMassa)
+	return max(reservaPublicacaoLote, 2*porPagina)
+}
+
+// GerarEmMassa cria o lote e publica em background uma mensagem por cliente do segmento.
+// Retorna assim que o lote é criado; o andamento é consultado por BuscarLote.
+func (s *ServicoRecomendacao) GerarEmMassa(opcoes dominio.FiltroItens, segmento dominio.SegmentoClientes) (*dominio.Lote, error) {
+	if err := segmento.Validar(); err != nil {
+		return nil, fmt.Errorf("%w: %v", dominio.ErrSegmentoInvalido, err)
+	}
+
+	// o total é uma estimativa até o fim da publicação, pois clientes podem entrar ou sair do segmento no meio
+	total, err := s.repo.ContarClientes(segmento)
+	if err != nil {
+		slog.Error("Erro ao contar clientes para geração em massa", "erro", err)
+		return nil, err
+	}
+
+	lote, err := s.repo.CriarLote(total, opcoes, segmento, s.reservaPublicacao())
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Iniciando geração em massa de recomendações", "id_lote", lote.ID, "total_clientes", total, "segmentado", !segmento.Vazio())
+
+	go s.publicarLote(*lote)
+
+	return lote, nil
+}
+
+// MonitorarLotes retoma os lotes interrompidos na inicialização e depois periodicamente, no
+// intervalo da reserva, para que lotes abandonados por falha de outra instância (ou desta)
+// não fiquem em andamento para sempre
+func (s *ServicoRecomendacao) MonitorarLotes(ctx context.Context) {
+	s.RetomarLotes()
+
+	go func() {
+		ticker := time.NewTicker(reservaPublicacaoLote)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RetomarLotes()
+			}
+		}
+	}()
+}
+
+// RetomarLotes reassume a publicação de lotes interrompidos (por exemplo, por reinício da
+// instância) a partir do último checkpoint gravado
+func (s *ServicoRecomendacao) RetomarLotes() {
+	lotes, err := s.repo.ReivindicarLotesPendentes(s.reservaPublicacao())
+	if err != nil {
+		slog.Error("Falha ao buscar lotes com publicação pendente", "erro", err)
+		return
+	}
+
+	for _, lote := range lotes {
+		slog.Info("Retomando publicação de lote", "id_lote", lote.ID, "publicados", lote.Publicados, "cursor", lote.Cursor)
+		go s.publicarLote(lote)
+	}
+}
+
+// publicarLote percorre os clientes em páginas a partir do cursor do lote, gravando um
+// checkpoint a cada página e parando se o lote for cancelado no meio. As mensagens vão para
+// um tópico de baixa prioridade, no ritmo configurado, para não atrasar as gerações individuais.
+func (s *ServicoRecomendacao) publicarLote(lote dominio.Lote) {
+	cursor, publicados := lote.Cursor, lote.Publicados
+
+	var ritmo <-chan time.Time
+	if s.taxaPublicacaoMassa > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(s.taxaPublicacaoMassa))
+		defer ticker.Stop()
+		ritmo = ticker.C
+	}
+
+	for {
+		clientes, err := s.listarPaginaLote(lote, cursor)
+		if err != nil {
+			slog.Error("Falha ao listar clientes do lote, publicação será retomada", "erro", err, "id_lote", lote.ID)
+			s.liberarPublicacao(lote.ID)
+			return
+		}
+		if len(clientes) == 0 {
+			break
+		}
+
+		for _, cliente := range clientes {
+			if ritmo != nil {
+				<-ritmo
+			}
+			err := s.publicador.Publicar(dominio.TopicoGerarRecomendacaoMassa, dominio.SolicitacaoGeracao{
+				ClienteID: cliente.ID,
+				LoteID:    lote.ID,
+				Opcoes:    lote.Opcoes,
+			})
+			if err != nil {
+				// a página é publicada de novo a partir do último checkpoint quando o lote for retomado
+				slog.Error("Falha ao publicar mensagem do lote, publicação será retomada", "erro", err, "id_lote", lote.ID, "cliente_id", cliente.ID)
+				s.liberarPublicacao(lote.ID)
+				return
+			}
+		}
+		cursor = clientes[len(clientes)-1].ID
+		publicados += len(clientes)
+
+		if !s.registrarPublicacao(lote.ID, publicados, cursor) {
+			return
+		}
+	}
+
+	if err := s.repo.FinalizarPublicacaoLote(lote.ID, publicados); err != nil {
+		slog.Error("Falha ao finalizar publicação do lote", "erro", err, "id_lote", lote.ID)
+		return
+	}
+	slog.Info("Publicação do lote finalizada", "id_lote", lote.ID, "publicados", publicados)
+}
+
+// listarPaginaLote lê a próxima página de clientes, repetindo com espera crescente em caso de
+// falha transitória do banco
+func (s *ServicoRecomendacao) listarPaginaLote(lote dominio.Lote, cursor string) ([]dominio.Cliente, error) {
+	espera := time.Second
+	for tentativa := 1; ; tentativa++ {
+		clientes, err := s.repo.ListarClientesApos(cursor, tamanhoPaginaClientes, lote.Segmento)
+		if err == nil || tentativa == tentativasPaginaLote {
+			return clientes, err
+		}
+		slog.Warn("Falha ao listar clientes do lote, tentando novamente", "erro", err, "id_lote", lote.ID, "tentativa", tentativa)
+		time.Sleep(espera)
+		espera *= 2
+	}
+}
+
+// registrarPublicacao grava o checkpoint, que também renova a reserva, e retorna false se a
+// publicação deve parar. Sem checkpoint a reserva pode expirar e outra instância assumiria o
+// lote em paralelo, então a publicação para e o lote é liberado para retomada.
+func (s *ServicoRecomendacao) registrarPublicacao(loteID string, publicados int, cursor string) bool {
+	if err := s.repo.AtualizarPublicacaoLote(loteID, publicados, cursor, s.reservaPublicacao()); err != nil {
+		slog.Error("Falha ao registrar checkpoint de publicação do lote, publicação será retomada", "erro", err, "id_lote", loteID, "publicados", publicados)
+		s.liberarPublicacao(loteID)
+		return false
+	}
+
+	lote, err := s.repo.BuscarLote(loteID)
+	if err != nil {
+		// sem conseguir ler o status, segue publicando: o worker também descarta lotes cancelados
+		slog.Error("Falha ao verificar status do lote", "erro", err, "id_lote", loteID)
+		return true
+	}
+	if lote.Status == dominio.LoteCancelado {
+		slog.Info("Publicação do lote interrompida por cancelamento", "id_lote", loteID, "publicados", publicados)
+		return false
+	}
+	return true
+}
+
+// liberarPublicacao devolve o lote para retomada; se nem isso funcionar, a reserva expira sozinha
+func (s *ServicoRecomendacao) liberarPublicacao(loteID string) {
+	if err := s.repo.LiberarPublicacaoLote(loteID); err != nil {
+		slog.Error("Falha ao liberar reserva de publicação do lote", "erro", err, "id_lote", loteID)
+	}
+}
+
+// loteCancelado indica se a mensagem pertence a um lote cancelado e deve ser descartada
+func (s *ServicoRecomendacao) loteCancelado(loteID string) bool {
+	if loteID == "" {
+		return false
+	}
+	lote, err := s.repo.BuscarLote(loteID)
+	if err != nil {
+		slog.Error("Falha ao verificar status do lote", "erro", err, "id_lote", loteID)
+		return false
+	}
+	return lote.Status == dominio.LoteCancelado
+}
+
+// registrarResultadoLote conta o cliente processado no lote, sem interromper a geração em caso de erro
+func (s *ServicoRecomendacao) registrarResultadoLote(loteID, clienteID string, sucesso bool) {
+	if loteID == "" {
+		return
+	}
+	if err := s.repo.RegistrarResultadoLote(loteID, clienteID, sucesso); err != nil {
+		slog.Error("Falha ao registrar resultado no lote", "erro", err, "id_lote", loteID, "cliente_id", clienteID)
+	}
+}
+
+// BuscarLote retorna o lote com o progresso e a estimativa de conclusão pelo ritmo médio
+func (s *ServicoRecomendacao) BuscarLote(id string) (*dominio.Lote, error) {
+	lote, err := s.repo.BuscarLote(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if lote.Total > 0 {
+		lote.Progresso = float64(lote.Processados()) / float64(lote.Total)
+	} else {
+		lote.Progresso = 1
+	}
+
+	if lote.Status == dominio.LoteEmAndamento && lote.Processados() > 0 {
+		decorrido := time.Since(lote.CriadoEm)
+		restantes := lote.Total - lote.Processados()
+		porCliente := decorrido / time.Duration(lote.Processados())
+		estimativa := time.Now().Add(porCliente * time.Duration(restantes))
+		lote.EstimativaConclusao = &estimativa
+	}
+	return lote, nil
+}
+
+// CancelarLote interrompe a publicação e faz o worker descartar as mensagens pendentes do lote
+func (s *ServicoRecomendacao) CancelarLote(id string) error {
+	cancelado, err := s.repo.CancelarLote(id)
+	if err != nil {
+		return err
+	}
+	if !cancelado {
+		// diferencia lote inexistente de lote já finalizado
+		if _, err := s.repo.BuscarLote(id); err != nil {
+			return err
+		}
+		return dominio.ErrLoteFinalizado
+	}
+
+	slog.Info("Lote de geração cancelado", "id_lote", id)
+	return nil
+}
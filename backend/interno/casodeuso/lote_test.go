@@ -0,0 +1,228 @@
+package casodeuso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// clientesLote gera n clientes moderados com ids em ordem lexicográfica
+func clientesLote(n int) []dominio.Cliente {
+	clientes := make([]dominio.Cliente, n)
+	for i := range clientes {
+		clientes[i] = dominio.Cliente{ID: fmt.Sprintf("c%04d", i), PerfilRisco: dominio.PerfilModerado, Patrimonio: 100000}
+	}
+	return clientes
+}
+
+// esperarPublicacao aguarda a goroutine de publicação finalizar o lote
+func esperarPublicacao(t *testing.T, repo *repositorioFake, loteID string) loteFake {
+	t.Helper()
+	limite := time.Now().Add(2 * time.Second)
+	for !repo.lote(loteID).PublicacaoFinalizada {
+		if time.Now().After(limite) {
+			t.Fatalf("publicação do lote não finalizou: %+v", repo.lote(loteID).Lote)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return repo.lote(loteID)
+}
+
+// clientesPublicados conta as mensagens de massa por cliente
+func clientesPublicados(t *testing.T, publicador *publicadorFake, loteID string) map[string]int {
+	t.Helper()
+	porCliente := make(map[string]int)
+	for _, m := range publicador.publicadas() {
+		sol := m.payload.(dominio.SolicitacaoGeracao)
+		if m.topico != dominio.TopicoGerarRecomendacaoMassa || sol.LoteID != loteID {
+			t.Fatalf("mensagem inesperada: %+v", m)
+		}
+		porCliente[sol.ClienteID]++
+	}
+	return porCliente
+}
+
+func TestGerarEmMassaPublicaTodasAsPaginas(t *testing.T) {
+	total := 2*tamanhoPaginaClientes + 7
+	clientes := clientesLote(total)
+	repo := novoRepositorioFake(clientes...)
+	publicador := &publicadorFake{}
+	servico := NovoServicoRecomendacao(repo, publicador)
+
+	lote, err := servico.GerarEmMassa(dominio.FiltroItens{Limite: 3}, dominio.SegmentoClientes{})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	final := esperarPublicacao(t, repo, lote.ID)
+
+	porCliente := clientesPublicados(t, publicador, lote.ID)
+	if len(porCliente) != total || len(publicador.publicadas()) != total {
+		t.Errorf("%d mensagens para %d clientes, esperado uma para cada um dos %d", len(publicador.publicadas()), len(porCliente), total)
+	}
+	if final.Publicados != total || final.Total != total || final.Cursor != clientes[total-1].ID {
+		t.Errorf("lote = %+v, esperado %d publicados com cursor no último cliente", final.Lote, total)
+	}
+}
+
+func TestRetomarLoteDoCheckpoint(t *testing.T) {
+	clientes := clientesLote(tamanhoPaginaClientes + 20)
+	repo := novoRepositorioFake(clientes...)
+	publicador := &publicadorFake{}
+	servico := NovoServicoRecomendacao(repo, publicador)
+
+	// instância anterior publicou a primeira página e parou; a reserva já expirou
+	lote, _ := repo.CriarLote(len(clientes), dominio.FiltroItens{}, dominio.SegmentoClientes{}, -time.Second)
+	repo.AtualizarPublicacaoLote(lote.ID, tamanhoPaginaClientes, clientes[tamanhoPaginaClientes-1].ID, -time.Second)
+
+	servico.RetomarLotes()
+	final := esperarPublicacao(t, repo, lote.ID)
+
+	porCliente := clientesPublicados(t, publicador, lote.ID)
+	if len(porCliente) != 20 {
+		t.Errorf("%d clientes publicados na retomada, esperado 20", len(porCliente))
+	}
+	if porCliente[clientes[tamanhoPaginaClientes-1].ID] != 0 || porCliente[clientes[tamanhoPaginaClientes].ID] != 1 {
+		t.Error("a retomada deveria começar no cliente seguinte ao cursor")
+	}
+	if final.Publicados != len(clientes) {
+		t.Errorf("publicados = %d, esperado %d", final.Publicados, len(clientes))
+	}
+}
+
+func TestRetomarLoteIgnoraReservaValida(t *testing.T) {
+	repo := novoRepositorioFake(clientesLote(3)...)
+	publicador := &publicadorFake{}
+	servico := NovoServicoRecomendacao(repo, publicador)
+
+	// outra instância ainda está publicando; sem reivindicação, nenhuma publicação é iniciada
+	repo.CriarLote(3, dominio.FiltroItens{}, dominio.SegmentoClientes{}, time.Minute)
+	servico.RetomarLotes()
+
+	if n := len(publicador.publicadas()); n != 0 {
+		t.Errorf("%d mensagens publicadas para um lote reservado por outra instância", n)
+	}
+}
+
+func TestResultadoLoteIdempotente(t *testing.T) {
+	repo := novoRepositorioFake(clientesLote(2)...)
+	repo.produtos = catalogoSimulacao()
+	servico := NovoServicoRecomendacao(repo, &publicadorFake{})
+	lote, _ := repo.CriarLote(2, dominio.FiltroItens{}, dominio.SegmentoClientes{}, time.Minute)
+	repo.FinalizarPublicacaoLote(lote.ID, 2)
+
+	processar := func(clienteID string) error {
+		_, err := servico.ProcessarSolicitacao(dominio.SolicitacaoGeracao{ClienteID: clienteID, LoteID: lote.ID})
+		return err
+	}
+
+	// mensagem reentregue do mesmo cliente conta uma vez
+	processar("c0000")
+	processar("c0000")
+	if l := repo.lote(lote.ID); l.Concluidos != 1 || l.Falhos != 0 || l.Status != dominio.LoteEmAndamento {
+		t.Fatalf("lote = %+v, esperado 1 concluído e ainda em andamento", l.Lote)
+	}
+
+	// a falha conclui o lote; o reprocessamento com sucesso substitui a falha
+	falha := errors.New("banco indisponível")
+	repo.aoListarProdutos = func(ctx context.Context) error { return falha }
+	if err := processar("c0001"); !errors.Is(err, falha) {
+		t.Fatalf("erro = %v, esperado %v", err, falha)
+	}
+	if l := repo.lote(lote.ID); l.Falhos != 1 || l.Status != dominio.LoteConcluido {
+		t.Fatalf("lote = %+v, esperado 1 falho e concluído", l.Lote)
+	}
+	repo.aoListarProdutos = nil
+	processar("c0001")
+	if l := repo.lote(lote.ID); l.Concluidos != 2 || l.Falhos != 0 {
+		t.Errorf("lote = %+v, esperado 2 concluídos e nenhuma falha", l.Lote)
+	}
+}
+
+func TestLoteCanceladoDescartaMensagens(t *testing.T) {
+	repo := novoRepositorioFake(clientesLote(1)...)
+	servico := NovoServicoRecomendacao(repo, &publicadorFake{})
+	lote, _ := repo.CriarLote(1, dominio.FiltroItens{}, dominio.SegmentoClientes{}, time.Minute)
+
+	if err := servico.CancelarLote(lote.ID); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	resultado, err := servico.ProcessarSolicitacao(dominio.SolicitacaoGeracao{ClienteID: "c0000", LoteID: lote.ID})
+	if resultado != nil || err != nil || len(repo.recomendacoes) != 0 {
+		t.Errorf("resultado = %+v, erro = %v; esperado mensagem descartada", resultado, err)
+	}
+	if err := servico.CancelarLote(lote.ID); !errors.Is(err, dominio.ErrLoteFinalizado) {
+		t.Errorf("erro = %v, esperado lote já finalizado", err)
+	}
+	if err := servico.CancelarLote("lote-9"); !errors.Is(err, dominio.ErrLoteNaoEncontrado) {
+		t.Errorf("erro = %v, esperado lote não encontrado", err)
+	}
+}
+
+// liberado indica que a reserva de publicação do lote não está mais valendo
+func (l loteFake) liberado() bool {
+	return !l.reservaAte.After(time.Now())
+}
+
+func TestPublicarLoteFalhaNoCheckpointLibera(t *testing.T) {
+	clientes := clientesLote(tamanhoPaginaClientes + 5)
+	repo := novoRepositorioFake(clientes...)
+	publicador := &publicadorFake{}
+	servico := NovoServicoRecomendacao(repo, publicador)
+	lote, _ := repo.CriarLote(len(clientes), dominio.FiltroItens{}, dominio.SegmentoClientes{}, time.Minute)
+
+	repo.falhaCheckpoint = errors.New("banco indisponível")
+	servico.publicarLote(*lote)
+
+	// para depois da primeira página, sem seguir publicando com a reserva vencendo
+	if n := len(publicador.publicadas()); n != tamanhoPaginaClientes {
+		t.Fatalf("%d mensagens publicadas, esperado só a primeira página (%d)", n, tamanhoPaginaClientes)
+	}
+	if l := repo.lote(lote.ID); !l.liberado() || l.PublicacaoFinalizada {
+		t.Fatalf("lote = %+v, esperado liberado e com a publicação pendente", l.Lote)
+	}
+
+	// a retomada republica a página sem checkpoint e termina o lote
+	repo.falhaCheckpoint = nil
+	servico.RetomarLotes()
+	final := esperarPublicacao(t, repo, lote.ID)
+	if porCliente := clientesPublicados(t, publicador, lote.ID); len(porCliente) != len(clientes) {
+		t.Errorf("%d clientes publicados, esperado %d", len(porCliente), len(clientes))
+	}
+	if final.Publicados != len(clientes) {
+		t.Errorf("publicados = %d, esperado %d", final.Publicados, len(clientes))
+	}
+}
+
+func TestPublicarLoteFalhaNaFilaLibera(t *testing.T) {
+	repo := novoRepositorioFake(clientesLote(3)...)
+	servico := NovoServicoRecomendacao(repo, &publicadorFake{falha: errors.New("pubsub fora do ar")})
+	lote, _ := repo.CriarLote(3, dominio.FiltroItens{}, dominio.SegmentoClientes{}, time.Minute)
+
+	servico.publicarLote(*lote)
+
+	if l := repo.lote(lote.ID); !l.liberado() || l.PublicacaoFinalizada || l.Publicados != 0 {
+		t.Errorf("lote = %+v, esperado liberado sem checkpoint", l.Lote)
+	}
+}
+
+func TestPublicarLoteCanceladoPara(t *testing.T) {
+	clientes := clientesLote(tamanhoPaginaClientes + 5)
+	repo := novoRepositorioFake(clientes...)
+	publicador := &publicadorFake{}
+	servico := NovoServicoRecomendacao(repo, publicador)
+	lote, _ := repo.CriarLote(len(clientes), dominio.FiltroItens{}, dominio.SegmentoClientes{}, time.Minute)
+
+	servico.CancelarLote(lote.ID)
+	servico.publicarLote(*lote)
+
+	if n := len(publicador.publicadas()); n != tamanhoPaginaClientes {
+		t.Errorf("%d mensagens publicadas, esperado parar no checkpoint da primeira página", n)
+	}
+	if l := repo.lote(lote.ID); l.PublicacaoFinalizada {
+		t.Errorf("lote = %+v, esperado publicação não finalizada", l.Lote)
+	}
+}
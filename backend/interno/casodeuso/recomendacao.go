@@ -1,184 +1,547 @@
 package casodeuso
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"sort"
-	"sync"
+	"sync/atomic"
+	"time"
 
 	"backend/interno/dominio"
 )
 
 type ServicoRecomendacao struct {
-	repo       dominio.RepositorioDados
-	publicador dominio.Publicador
+	repo        dominio.RepositorioDados
+	publicador  dominio.Publicador
+	estrategias *RegistroEstrategias
+	filtros     []FiltroElegibilidade
+	config      atomic.Pointer[ConfigPontuacao]
+	// taxaPublicacaoMassa limita as mensagens por segundo da geração em massa; zero não limita
+	taxaPublicacaoMassa int
+	// sincronos limita as gerações calculadas dentro da requisição (modo síncrono)
+	sincronos chan struct{}
 }
 
+// maxGeracoesSincronas é quantas gerações síncronas rodam ao mesmo tempo na instância;
+// acima disso a solicitação vai direto para o worker
+const maxGeracoesSincronas = 16
+
 func NovoServicoRecomendacao(r dominio.RepositorioDados, p dominio.Publicador) *ServicoRecomendacao {
-	return &ServicoRecomendacao{repo: r, publicador: p}
+	s := &ServicoRecomendacao{
+		repo:        r,
+		publicador:  p,
+		estrategias: EstrategiasPadrao(),
+		filtros:     filtrosPadrao(),
+		sincronos:   make(chan struct{}, maxGeracoesSincronas),
+	}
+	cfg := ConfigPadrao()
+	s.config.Store(&cfg)
+	return s
 }
 
-// Executar roda a lógica de scoring definida no projeto
-func (s *ServicoRecomendacao) Executar(clienteID string) (*dominio.ResultadoRecomendacao, error) {
-	slog.Info("Iniciando cálculo de recomendação", "cliente_id", clienteID)
+// AtualizarConfig troca os pesos e limiares usados nos próximos cálculos.
+// Cálculos em andamento continuam com a configuração que já haviam carregado.
+func (s *ServicoRecomendacao) AtualizarConfig(cfg ConfigPontuacao) error {
+	if err := cfg.Validar(); err != nil {
+		return err
+	}
+	for _, variante := range cfg.Experimento.Variantes {
+		if _, existe := s.estrategias.Obter(variante.Estrategia); !existe {
+			return fmt.Errorf("estratégia da variante %s não registrada: %s", variante.Nome, variante.Estrategia)
+		}
+	}
+	anterior := s.config.Swap(&cfg)
+	slog.Info("Configuração de pontuação atualizada", "versao_anterior", anterior.Versao, "versao", cfg.Versao)
+	return nil
+}
+
+// TaxaPublicacaoMassaMaxima limita a taxa configurável; acima disso o intervalo entre
+// mensagens fica abaixo do que o ticker consegue representar com folga
+const TaxaPublicacaoMassaMaxima = 1000
+
+// ConfigurarPublicacaoMassa define quantas mensagens por segundo a geração em massa publica,
+// para não saturar o worker. Deve ser chamado antes de MonitorarLotes.
+func (s *ServicoRecomendacao) ConfigurarPublicacaoMassa(mensagensPorSegundo int) error {
+	if mensagensPorSegundo < 0 || mensagensPorSegundo > TaxaPublicacaoMassaMaxima {
+		return fmt.Errorf("taxa de publicação em massa deve estar entre 0 e %d: %d", TaxaPublicacaoMassaMaxima, mensagensPorSegundo)
+	}
+	s.taxaPublicacaoMassa = mensagensPorSegundo
+	return nil
+}
+
+// ConfigAtual retorna a configuração de pontuação em uso
+func (s *ServicoRecomendacao) ConfigAtual() ConfigPontuacao {
+	return *s.config.Load()
+}
+
+// Regras expõe o registro de regras da estratégia padrão para habilitar, desabilitar ou reordenar regras
+func (s *ServicoRecomendacao) Regras() *RegistroRegras {
+	regras, _ := s.estrategias.Obter(EstrategiaPadrao)
+	return regras
+}
+
+// Estrategias expõe o registro de estratégias para incluir novas estratégias de scoring
+func (s *ServicoRecomendacao) Estrategias() *RegistroEstrategias {
+	return s.estrategias
+}
+
+// regrasDoCliente sorteia a variante do experimento e retorna as regras da estratégia correspondente
+func (s *ServicoRecomendacao) regrasDoCliente(clienteID string, cfg ConfigPontuacao) (VarianteExperimento, []RegraPontuacao) {
+	variante := SortearVariante(clienteID, cfg.Experimento)
+	registro, existe := s.estrategias.Obter(variante.Estrategia)
+	if !existe {
+		// AtualizarConfig já valida as estratégias; cai na padrão apenas por segurança
+		slog.Warn("Estratégia não registrada, usando a padrão", "estrategia", variante.Estrategia, "cliente_id", clienteID)
+		variante.Estrategia = EstrategiaPadrao
+		registro, _ = s.estrategias.Obter(EstrategiaPadrao)
+	}
+	return variante, registro.Ativas()
+}
 
-	cliente, err := s.repo.ObterCliente(clienteID)
+// Executar roda a lógica de scoring definida no projeto.
+// As opções restringem os itens persistidos (tipos, riscos, pontuação mínima e limite).
+func (s *ServicoRecomendacao) Executar(clienteID string, opcoes dominio.FiltroItens) (*dominio.ResultadoRecomendacao, error) {
+	ctx := context.Background()
+	cliente, err := s.repo.ObterCliente(ctx, clienteID)
 	if err != nil {
 		slog.Error("Falha ao obter dados do cliente", "erro", err, "cliente_id", clienteID)
 		return nil, err
 	}
+	return s.executar(ctx, cliente, opcoes, nil)
+}
+
+// executar interrompe o cálculo entre as etapas quando o contexto termina. antesDeSalvar, se
+// informado, roda logo antes da gravação e a impede ao retornar erro; a gravação em si não é
+// cancelada pelo contexto, para que o que foi autorizado por antesDeSalvar chegue ao banco.
+func (s *ServicoRecomendacao) executar(ctx context.Context, cliente *dominio.Cliente, opcoes dominio.FiltroItens, antesDeSalvar func() error) (*dominio.ResultadoRecomendacao, error) {
+	clienteID := cliente.ID
+	slog.Info("Iniciando cálculo de recomendação", "cliente_id", clienteID)
 
-	produtos, err := s.repo.ListarProdutosAtivos()
+	produtos, err := s.repo.ListarProdutosAtivos(ctx)
 	if err != nil {
 		slog.Error("Falha ao listar produtos ativos", "erro", err)
 		return nil, err
 	}
 
-	// estrutura para coletar resultados das goroutines
-	type resultadoScore struct {
-		item dominio.RecomendacaoItem
-		ok   bool
-	}
-
-	// canal bufferizado para evitar bloqueio
-	canal := make(chan resultadoScore, len(produtos))
-	var wg sync.WaitGroup
-
-	// Semáforo para limitar concorrência de acesso ao banco (max 10 workers)
-	sem := make(chan struct{}, 10)
-
-	// processa cada produto em paralelo (goroutines controladas pelo semáforo)
-	for _, p := range produtos {
-		wg.Add(1)
-		go func(prod dominio.Produto) {
-			// Adquire token
-			sem <- struct{}{}
-			defer func() {
-				// Libera token e sinaliza conclusão
-				<-sem
-				wg.Done()
-			}()
-			score := 0.0
-			motivo := ""
-
-			// 1. regra de compatibilidade de perfil
-			matchRisco := false
-			if cliente.PerfilRisco == "Conservador" && prod.RiscoAssociado == "Baixo" {
-				score += 0.3
-				matchRisco = true
-			} else if cliente.PerfilRisco == "Moderado" && prod.RiscoAssociado == "Médio" {
-				score += 0.25
-				matchRisco = true
-			} else if cliente.PerfilRisco == "Arrojado" && prod.RiscoAssociado == "Alto" {
-				score += 0.2
-				matchRisco = true
-			}
-			if matchRisco {
-				motivo += "[perfil compativel] "
-			}
-
-			// 2. regra de rentabilidade (>10%)
-			if prod.Rentabilidade12m > 10.0 {
-				score += 0.1
-				motivo += "[boa rentabilidade] "
-			}
-
-			// 3. regra de acessibilidade (< 5% patrimonio)
-			if cliente.Patrimonio > 0 && prod.AplicacaoMinima < (cliente.Patrimonio*0.05) {
-				score += 0.1
-				motivo += "[acessivel] "
-			}
-
-			// 4. regra de diversificacao (penaliza se ja tem)
-			jaTem, err := s.repo.VerificarPosseProduto(cliente.ID, prod.ID)
-			if err != nil {
-				slog.Error("falha ao verificar posse de produto", "err", err, "clienteID", cliente.ID, "produtoID", prod.ID)
-			} else if jaTem {
-				score -= 0.2
-			}
+	// carrega de uma vez as posições e interações do cliente para evitar consultas por produto
+	posicoes, err := s.repo.ListarPosicoes(ctx, cliente.ID)
+	if err != nil {
+		slog.Error("Falha ao calcular posição do cliente", "erro", err, "cliente_id", clienteID)
+		return nil, err
+	}
 
-			// 5. regra de interesse (se interagiu recentemente)
-			interagiu, err := s.repo.VerificarInteracaoRecente(cliente.ID, prod.ID)
-			if err != nil {
-				slog.Error("falha ao verificar interacao recente", "err", err, "clienteID", cliente.ID, "produtoID", prod.ID)
-			} else if interagiu {
-				score += 0.15
-				motivo += "[interesse recente] "
-			}
+	cfg := s.ConfigAtual()
+	agora := time.Now()
 
-			// envia para o canal se tiver pontuação positiva
-			if score > 0 {
-				canal <- resultadoScore{
-					item: dominio.RecomendacaoItem{Produto: prod, Pontuacao: score, Motivo: motivo},
-					ok:   true,
-				}
-			} else {
-				canal <- resultadoScore{ok: false}
-			}
-		}(p)
+	// apenas interações dentro da janela configurada contam como interesse
+	desde := agora.AddDate(0, 0, -cfg.Interesse.JanelaDias)
+	interacoes, err := s.repo.ListarInteracoes(ctx, cliente.ID, desde)
+	if err != nil {
+		slog.Error("Falha ao listar interações do cliente", "erro", err, "cliente_id", clienteID)
+		return nil, err
 	}
 
-	// fecha o canal quando todas as goroutines terminarem
-	go func() {
-		wg.Wait()
-		close(canal)
-	}()
+	ctxPontuacao := novoContextoPontuacao(cliente, cfg, agora, posicoes, interacoes)
+	ctxPontuacao.Supressoes, err = s.carregarSupressoes(ctx, cliente.ID, cfg, agora)
+	if err != nil {
+		slog.Error("Falha ao listar supressões do cliente", "erro", err, "cliente_id", clienteID)
+		return nil, err
+	}
 
-	var recomendacoes []dominio.RecomendacaoItem
-	for res := range canal {
-		if res.ok {
-			recomendacoes = append(recomendacoes, res.item)
+	// o histórico completo só é consultado para as estratégias que o usam (legado)
+	variante, regras := s.regrasDoCliente(cliente.ID, cfg)
+	if precisaHistorico(regras) {
+		ctxPontuacao.ProdutosAplicados, ctxPontuacao.ProdutosInteragidos, err = s.repo.ListarHistoricoProdutos(ctx, cliente.ID)
+		if err != nil {
+			slog.Error("Falha ao listar histórico de produtos do cliente", "erro", err, "cliente_id", clienteID)
+			return nil, err
 		}
 	}
-
-	// ordena por pontuação decrescente
-	sort.Slice(recomendacoes, func(i, j int) bool {
-		return recomendacoes[i].Pontuacao > recomendacoes[j].Pontuacao
-	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	recomendacoes, exclusoes := s.calcular(ctxPontuacao, regras, produtos, opcoes)
 
 	slog.Info("Cálculo finalizado",
 		"cliente_id", clienteID,
 		"total_recomendacoes", len(recomendacoes),
 		"produtos_analisados", len(produtos),
+		"produtos_excluidos", len(exclusoes),
+		"versao_config", ctxPontuacao.Config.Versao,
+		"estrategia", variante.Estrategia,
+		"variante", variante.Nome,
 	)
 
+	resultado := &dominio.ResultadoRecomendacao{
+		ClienteID:     cliente.ID,
+		VersaoConfig:  ctxPontuacao.Config.Versao,
+		Estrategia:    variante.Estrategia,
+		Variante:      variante.Nome,
+		Recomendacoes: recomendacoes,
+		Exclusoes:     exclusoes,
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if antesDeSalvar != nil {
+		if err := antesDeSalvar(); err != nil {
+			return nil, err
+		}
+	}
+
 	// persiste no banco (auditoria) e recupera uuid
-	uuid, err := s.repo.SalvarRecomendacao(clienteID, recomendacoes)
+	uuid, err := s.repo.SalvarRecomendacao(context.WithoutCancel(ctx), resultado)
 	if err != nil {
 		slog.Error("Erro ao salvar recomendação no banco (auditoria)", "erro", err, "cliente_id", clienteID)
 		return nil, err
 	}
+	resultado.ID = uuid
 
-	return &dominio.ResultadoRecomendacao{
-		ID:            uuid,
-		ClienteID:     cliente.ID,
-		Recomendacoes: recomendacoes,
-	}, nil
+	return resultado, nil
+}
+
+// novoContextoPontuacao indexa os dados do cliente carregados para o cálculo
+func novoContextoPontuacao(cliente *dominio.Cliente, cfg ConfigPontuacao, agora time.Time, posicoes []dominio.Posicao, interacoes []dominio.Interacao) *ContextoPontuacao {
+	ctx := &ContextoPontuacao{
+		Cliente:        cliente,
+		Config:         cfg,
+		DataReferencia: agora,
+		Posicoes:       indexarPosicoesPorProduto(posicoes),
+		Interacoes:     agruparInteracoesPorProduto(interacoes),
+	}
+	ctx.Exposicao = CalcularExposicao(ctx.Posicoes)
+
+	// sem o histórico completo, as posições e interações carregadas são a melhor aproximação
+	ctx.ProdutosAplicados = make(map[string]bool, len(ctx.Posicoes))
+	for produtoID := range ctx.Posicoes {
+		ctx.ProdutosAplicados[produtoID] = true
+	}
+	ctx.ProdutosInteragidos = make(map[string]bool, len(ctx.Interacoes))
+	for produtoID := range ctx.Interacoes {
+		ctx.ProdutosInteragidos[produtoID] = true
+	}
+	return ctx
+}
+
+// calcular aplica elegibilidade, scoring, ordenação, filtros e re-ranqueamento sem acessar o banco
+func (s *ServicoRecomendacao) calcular(ctx *ContextoPontuacao, regras []RegraPontuacao, produtos []dominio.Produto, opcoes dominio.FiltroItens) ([]dominio.RecomendacaoItem, []dominio.ExclusaoProduto) {
+	var recomendacoes []dominio.RecomendacaoItem
+	var exclusoes []dominio.ExclusaoProduto
+	for _, prod := range produtos {
+		// filtros de elegibilidade rodam antes do scoring; o produto barrado não é pontuado
+		if exclusao := s.avaliarElegibilidade(ctx, prod); exclusao != nil {
+			exclusoes = append(exclusoes, *exclusao)
+			continue
+		}
+
+		item := pontuarProduto(ctx, regras, prod)
+		// mantém apenas produtos com pontuação positiva
+		if item.Pontuacao > 0 {
+			recomendacoes = append(recomendacoes, item)
+		}
+	}
+
+	// ordena por pontuação decrescente
+	sort.SliceStable(recomendacoes, func(i, j int) bool {
+		return recomendacoes[i].Pontuacao > recomendacoes[j].Pontuacao
+	})
+
+	// filtra antes do re-ranqueamento para que a diversidade seja calculada só entre itens válidos
+	recomendacoes = opcoes.Filtrar(recomendacoes)
+
+	// evita que o topo da lista seja ocupado por produtos quase idênticos e aplica o top-N
+	recomendacoes = Reranquear(recomendacoes, ctx.Config.Reranqueamento)
+	return opcoes.Limitar(recomendacoes), exclusoes
+}
+
+// avaliarElegibilidade retorna a primeira exclusão encontrada pelos filtros, ou nil se o produto é elegível
+func (s *ServicoRecomendacao) avaliarElegibilidade(ctx *ContextoPontuacao, prod dominio.Produto) *dominio.ExclusaoProduto {
+	for _, filtro := range s.filtros {
+		if exclusao := filtro.Avaliar(ctx, prod); exclusao != nil {
+			return exclusao
+		}
+	}
+	return nil
+}
+
+// pontuarProduto aplica as regras em ordem e monta o item com a explicação de cada contribuição
+func pontuarProduto(ctx *ContextoPontuacao, regras []RegraPontuacao, prod dominio.Produto) dominio.RecomendacaoItem {
+	item := dominio.RecomendacaoItem{Produto: prod}
+
+	for _, regra := range regras {
+		res, err := regra.Avaliar(ctx, prod)
+		if err != nil {
+			slog.Error("falha ao avaliar regra de pontuação", "err", err, "regra", regra.ID(), "clienteID", ctx.Cliente.ID, "produtoID", prod.ID)
+			continue
+		}
+		if res.Pontos == 0 {
+			continue
+		}
+		item.Pontuacao += res.Pontos
+		if res.Motivo != "" {
+			item.Motivo += "[" + res.Motivo + "] "
+		}
+		item.Explicacao = append(item.Explicacao, dominio.ContribuicaoRegra{
+			RegraID:   regra.ID(),
+			Pontos:    res.Pontos,
+			Descricao: res.Motivo,
+			Entradas:  res.Entradas,
+		})
+	}
+
+	return item
+}
+
+// indexarPosicoesPorProduto indexa as posições pelo ID do produto
+func indexarPosicoesPorProduto(posicoes []dominio.Posicao) map[string]dominio.Posicao {
+	porProduto := make(map[string]dominio.Posicao, len(posicoes))
+	for _, p := range posicoes {
+		porProduto[p.ProdutoID] = p
+	}
+	return porProduto
+}
+
+// agruparInteracoesPorProduto indexa as interações pelo ID do produto
+func agruparInteracoesPorProduto(interacoes []dominio.Interacao) map[string][]dominio.Interacao {
+	porProduto := make(map[string][]dominio.Interacao)
+	for _, i := range interacoes {
+		porProduto[i.ProdutoID] = append(porProduto[i.ProdutoID], i)
+	}
+	return porProduto
+}
+
+// OpcoesBusca controla o que é montado além da recomendação persistida
+type OpcoesBusca struct {
+	Filtro          dominio.FiltroItens
+	IncluirAlocacao bool // calcula a proposta de alocação com o patrimônio atual do cliente
 }
 
 // BuscarUltima recupera a última recomendação gerada para o cliente
-func (s *ServicoRecomendacao) BuscarUltima(clienteID string) (*dominio.ResultadoRecomendacao, error) {
-	return s.repo.BuscarUltimaRecomendacao(clienteID)
+func (s *ServicoRecomendacao) BuscarUltima(clienteID string, opcoes OpcoesBusca) (*dominio.ResultadoRecomendacao, error) {
+	resultado, err := s.repo.BuscarUltimaRecomendacao(clienteID)
+	if err != nil || resultado == nil {
+		return resultado, err
+	}
+
+	// a alocação considera apenas os itens que sobraram após o filtro
+	resultado.Recomendacoes = opcoes.Filtro.Aplicar(resultado.Recomendacoes)
+
+	if opcoes.IncluirAlocacao {
+		cliente, err := s.repo.ObterCliente(context.Background(), clienteID)
+		if err != nil {
+			slog.Error("Falha ao obter dados do cliente para alocação", "erro", err, "cliente_id", clienteID)
+			return nil, err
+		}
+		resultado.Alocacao = CalcularAlocacao(resultado.Recomendacoes, cliente, s.ConfigAtual().Alocacao)
+	}
+
+	return resultado, nil
 }
 
-// SolicitarGeracao publica uma mensagem no tópico para gerar recomendação de forma assíncrona
-func (s *ServicoRecomendacao) SolicitarGeracao(clienteID string) {
-	s.publicador.Publicar("gerar-recomendacao", clienteID)
+// ObterPosicao retorna o saldo líquido atual do cliente em cada produto
+func (s *ServicoRecomendacao) ObterPosicao(clienteID string) (*dominio.PosicaoCliente, error) {
+	ctx := context.Background()
+	if _, err := s.repo.ObterCliente(ctx, clienteID); err != nil {
+		return nil, err
+	}
+
+	posicoes, err := s.repo.ListarPosicoes(ctx, clienteID)
+	if err != nil {
+		slog.Error("Falha ao calcular posição do cliente", "erro", err, "cliente_id", clienteID)
+		return nil, err
+	}
+
+	resultado := &dominio.PosicaoCliente{ClienteID: clienteID, Posicoes: posicoes}
+	for _, p := range posicoes {
+		resultado.SaldoTotal += p.Saldo
+	}
+	return resultado, nil
 }
 
-// GerarEmMassa dispara o processo de recomendação para todos os clientes de forma assíncrona
-func (s *ServicoRecomendacao) GerarEmMassa() error {
-	clientes, err := s.repo.ListarTodosClientes()
+// ExecutarComPrazo cria um job e roda a geração esperando o resultado até o fim do contexto.
+// Se o prazo acabar antes, o job é publicado para o worker e ErrPrazoExcedido é retornado
+// junto com o job, que registra o resultado quando a geração terminar.
+//
+// O job fica pendente durante o cálculo e só é reivindicado logo antes da gravação; o worker
+// também precisa reivindicá-lo, então apenas um dos dois grava a recomendação.
+func (s *ServicoRecomendacao) ExecutarComPrazo(ctx context.Context, clienteID string, opcoes dominio.FiltroItens) (*dominio.ResultadoRecomendacao, *dominio.Job, error) {
+	// valida o cliente antes de criar o job, para não deixar jobs órfãos de clientes inexistentes
+	cliente, err := s.repo.ObterCliente(ctx, clienteID)
 	if err != nil {
-		slog.Error("Erro ao listar clientes para geração em massa", "erro", err)
-		return err
+		return nil, nil, err
+	}
+
+	job, err := s.repo.CriarJob(ctx, clienteID)
+	if err != nil {
+		return nil, nil, err
 	}
+	solicitacao := dominio.SolicitacaoGeracao{ClienteID: clienteID, JobID: job.ID, Opcoes: opcoes}
 
-	slog.Info("Iniciando geração em massa de recomendações", "total_clientes", len(clientes))
+	select {
+	case s.sincronos <- struct{}{}:
+	default:
+		slog.Warn("Limite de gerações síncronas atingido, solicitação repassada ao worker", "cliente_id", clienteID, "id_job", job.ID)
+		if err := s.repassarAoWorker(solicitacao); err != nil {
+			return nil, job, err
+		}
+		return nil, job, dominio.ErrPrazoExcedido
+	}
 
-	for _, cliente := range clientes {
-		// Publica evento para cada cliente
-		s.SolicitarGeracao(cliente.ID)
+	type retorno struct {
+		resultado *dominio.ResultadoRecomendacao
+		err       error
 	}
+	// buffer de 1 para a goroutine não ficar bloqueada quando ninguém mais espera o resultado
+	pronto := make(chan retorno, 1)
 
-	return nil
+	go func() {
+		defer func() { <-s.sincronos }()
+
+		reivindicado := false
+		resultado, err := s.executar(ctx, cliente, opcoes, func() error {
+			ok, err := s.repo.ReivindicarJob(ctx, job.ID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errJobReivindicado
+			}
+			reivindicado = true
+			return nil
+		})
+
+		// o status é gravado aqui, e não por quem espera, porque a gravação reivindicada
+		// termina mesmo depois do prazo
+		switch {
+		case reivindicado && err == nil:
+			s.atualizarJob(job.ID, dominio.JobConcluido, resultado.ID, "")
+		case reivindicado:
+			s.atualizarJob(job.ID, dominio.JobFalhou, "", err.Error())
+		case ctx.Err() == nil:
+			// falhou antes da gravação e dentro do prazo: não haverá repasse, então o job é encerrado
+			s.falharJobPendente(job.ID, err.Error())
+		}
+		pronto <- retorno{resultado, err}
+	}()
+
+	select {
+	case r := <-pronto:
+		if r.err == nil {
+			return r.resultado, job, nil
+		}
+		if ctx.Err() == nil {
+			return nil, job, r.err
+		}
+		// o cálculo parou por causa do prazo: segue para o repasse ao worker
+	case <-ctx.Done():
+	}
+
+	slog.Warn("Prazo da geração síncrona excedido, solicitação repassada ao worker", "cliente_id", clienteID, "id_job", job.ID, "erro", ctx.Err())
+	if err := s.repassarAoWorker(solicitacao); err != nil {
+		return nil, job, err
+	}
+	return nil, job, dominio.ErrPrazoExcedido
+}
+
+// repassarAoWorker publica a solicitação do job. Se a publicação falhar, o job que ninguém
+// reivindicou é marcado como falho e ErrFilaIndisponivel é retornado; se a geração síncrona
+// já o reivindicou, ela registra o resultado e o repasse não faz falta.
+func (s *ServicoRecomendacao) repassarAoWorker(sol dominio.SolicitacaoGeracao) error {
+	err := s.publicador.Publicar(dominio.TopicoGerarRecomendacao, sol)
+	if err == nil {
+		return nil
+	}
+	slog.Error("Falha ao publicar solicitação de geração", "erro", err, "cliente_id", sol.ClienteID, "id_job", sol.JobID)
+	if !s.falharJobPendente(sol.JobID, "falha ao publicar na fila: "+err.Error()) {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", dominio.ErrFilaIndisponivel, err)
+}
+
+// errJobReivindicado indica que outro processo já assumiu o job
+var errJobReivindicado = errors.New("job já reivindicado")
+
+// falharJobPendente marca como falho um job que ninguém reivindicou. Retorna false apenas
+// quando outro processo já é dono do job e vai registrar o resultado.
+func (s *ServicoRecomendacao) falharJobPendente(jobID, causa string) bool {
+	ok, err := s.repo.ReivindicarJob(context.Background(), jobID)
+	if err != nil {
+		slog.Error("Falha ao reivindicar job para registrar a falha", "erro", err, "id_job", jobID)
+		return true
+	}
+	if ok {
+		s.atualizarJob(jobID, dominio.JobFalhou, "", causa)
+	}
+	return ok
+}
+
+// ProcessarSolicitacao executa uma solicitação de geração registrando o andamento no job
+// ou no lote. Mensagens sem job (formato antigo) apenas executam o cálculo. Mensagens de
+// lotes cancelados e de jobs já reivindicados por outro processo são descartadas e retornam
+// resultado nil sem erro.
+func (s *ServicoRecomendacao) ProcessarSolicitacao(sol dominio.SolicitacaoGeracao) (*dominio.ResultadoRecomendacao, error) {
+	if s.loteCancelado(sol.LoteID) {
+		slog.Info("Mensagem de lote cancelado descartada", "id_lote", sol.LoteID, "cliente_id", sol.ClienteID)
+		return nil, nil
+	}
+
+	if sol.JobID != "" {
+		ok, err := s.repo.ReivindicarJob(context.Background(), sol.JobID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			slog.Info("Job já reivindicado, mensagem descartada", "id_job", sol.JobID, "cliente_id", sol.ClienteID)
+			return nil, nil
+		}
+	}
+
+	resultado, err := s.Executar(sol.ClienteID, sol.Opcoes)
+	if err != nil {
+		s.atualizarJob(sol.JobID, dominio.JobFalhou, "", err.Error())
+		s.registrarResultadoLote(sol.LoteID, sol.ClienteID, false)
+		return nil, err
+	}
+
+	s.atualizarJob(sol.JobID, dominio.JobConcluido, resultado.ID, "")
+	s.registrarResultadoLote(sol.LoteID, sol.ClienteID, true)
+	return resultado, nil
+}
+
+// atualizarJob não interrompe a geração se o acompanhamento falhar; o erro fica no log
+func (s *ServicoRecomendacao) atualizarJob(jobID string, status dominio.StatusJob, recomendacaoID, erro string) {
+	if jobID == "" {
+		return
+	}
+	if err := s.repo.AtualizarJob(jobID, status, recomendacaoID, erro); err != nil {
+		slog.Error("Falha ao atualizar status do job", "erro", err, "id_job", jobID, "status", status)
+	}
+}
+
+// BuscarJob retorna o andamento de uma solicitação de geração
+func (s *ServicoRecomendacao) BuscarJob(id string) (*dominio.Job, error) {
+	return s.repo.BuscarJob(id)
+}
+
+// SolicitarGeracao cria o job e publica uma mensagem no tópico para gerar recomendação de forma assíncrona
+func (s *ServicoRecomendacao) SolicitarGeracao(clienteID string, opcoes dominio.FiltroItens) (*dominio.Job, error) {
+	ctx := context.Background()
+	if _, err := s.repo.ObterCliente(ctx, clienteID); err != nil {
+		return nil, err
+	}
+
+	job, err := s.repo.CriarJob(ctx, clienteID)
+	if err != nil {
+		slog.Error("Falha ao criar job de geração", "erro", err, "cliente_id", clienteID)
+		return nil, err
+	}
+
+	// sem a mensagem o job nunca rodaria: ele é encerrado como falho e o erro volta ao chamador
+	if err := s.repassarAoWorker(dominio.SolicitacaoGeracao{ClienteID: clienteID, JobID: job.ID, Opcoes: opcoes}); err != nil {
+		return nil, err
+	}
+	return job, nil
 }
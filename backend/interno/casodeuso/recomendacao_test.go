@@ -0,0 +1,250 @@
+package casodeuso
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+func TestBuscarUltima(t *testing.T) {
+	recomendacao := dominio.ResultadoRecomendacao{ID: "r1", ClienteID: "c1", Recomendacoes: []dominio.RecomendacaoItem{
+		itemAlocacao("p1", dominio.RiscoBaixo, 1, 0),
+	}}
+
+	casos := []struct {
+		nome     string
+		clientes []dominio.Cliente
+		alocacao bool
+		erro     error
+		alocado  bool
+	}{
+		{"sem alocação não consulta o cliente", nil, false, nil, false},
+		{"alocação com o patrimônio do cliente", []dominio.Cliente{moderado}, true, nil, true},
+		{"alocação de cliente inexistente", nil, true, dominio.ErrClienteNaoEncontrado, false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			repo := novoRepositorioFake(c.clientes...)
+			repo.recomendacoes = []dominio.ResultadoRecomendacao{recomendacao}
+			servico := NovoServicoRecomendacao(repo, nil)
+
+			resultado, err := servico.BuscarUltima("c1", OpcoesBusca{IncluirAlocacao: c.alocacao})
+			if !errors.Is(err, c.erro) {
+				t.Fatalf("erro = %v, esperado %v", err, c.erro)
+			}
+			if err == nil && (resultado.Alocacao != nil) != c.alocado {
+				t.Errorf("alocação = %+v, esperado presente = %v", resultado.Alocacao, c.alocado)
+			}
+		})
+	}
+}
+
+func TestBuscarUltimaSemRecomendacao(t *testing.T) {
+	servico := NovoServicoRecomendacao(novoRepositorioFake(moderado), nil)
+
+	resultado, err := servico.BuscarUltima("c1", OpcoesBusca{IncluirAlocacao: true})
+	if err != nil || resultado != nil {
+		t.Errorf("resultado = %+v, erro = %v; esperado nenhum resultado", resultado, err)
+	}
+}
+
+// novoServicoComPrazo monta o serviço com o catálogo de simulação e o cliente moderado
+func novoServicoComPrazo() (*ServicoRecomendacao, *repositorioFake, *publicadorFake) {
+	repo := novoRepositorioFake(moderado)
+	repo.produtos = catalogoSimulacao()
+	publicador := &publicadorFake{}
+	return NovoServicoRecomendacao(repo, publicador), repo, publicador
+}
+
+// esperarStatus aguarda a goroutine da geração síncrona gravar o status final do job
+func esperarStatus(t *testing.T, repo *repositorioFake, jobID string, status dominio.StatusJob) {
+	t.Helper()
+	limite := time.Now().Add(2 * time.Second)
+	for repo.statusJob(jobID) != status {
+		if time.Now().After(limite) {
+			t.Fatalf("status do job = %s, esperado %s", repo.statusJob(jobID), status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestExecutarComPrazoConcluido(t *testing.T) {
+	servico, repo, publicador := novoServicoComPrazo()
+
+	resultado, job, err := servico.ExecutarComPrazo(context.Background(), "c1", dominio.FiltroItens{})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	esperarStatus(t, repo, job.ID, dominio.JobConcluido)
+
+	if resultado.ID == "" || len(repo.recomendacoes) != 1 {
+		t.Errorf("resultado = %+v, gravadas = %d; esperado uma recomendação gravada", resultado, len(repo.recomendacoes))
+	}
+	if repo.consultasCliente != 1 {
+		t.Errorf("cliente consultado %d vezes, esperado 1", repo.consultasCliente)
+	}
+	if len(publicador.publicadas()) != 0 {
+		t.Errorf("mensagens = %+v, esperado nenhum repasse", publicador.publicadas())
+	}
+}
+
+func TestExecutarComPrazoFalhaAntesDaGravacao(t *testing.T) {
+	servico, repo, publicador := novoServicoComPrazo()
+	falha := errors.New("banco indisponível")
+	repo.aoListarProdutos = func(context.Context) error { return falha }
+
+	_, job, err := servico.ExecutarComPrazo(context.Background(), "c1", dominio.FiltroItens{})
+	if !errors.Is(err, falha) {
+		t.Fatalf("erro = %v, esperado %v", err, falha)
+	}
+	esperarStatus(t, repo, job.ID, dominio.JobFalhou)
+	if len(publicador.publicadas()) != 0 {
+		t.Errorf("mensagens = %+v, esperado nenhum repasse", publicador.publicadas())
+	}
+}
+
+func TestExecutarComPrazoExcedidoRepassaAoWorker(t *testing.T) {
+	servico, repo, publicador := novoServicoComPrazo()
+	// a geração síncrona só avança depois que o prazo acaba; o worker usa um contexto sem
+	// prazo (Done nil) e segue direto
+	repo.aoListarProdutos = func(ctx context.Context) error {
+		if done := ctx.Done(); done != nil {
+			<-done
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, job, err := servico.ExecutarComPrazo(ctx, "c1", dominio.FiltroItens{})
+	if !errors.Is(err, dominio.ErrPrazoExcedido) {
+		t.Fatalf("erro = %v, esperado prazo excedido", err)
+	}
+	if status := repo.statusJob(job.ID); status != dominio.JobPendente {
+		t.Fatalf("status do job = %s, esperado pendente até o worker reivindicar", status)
+	}
+
+	mensagens := publicador.publicadas()
+	if len(mensagens) != 1 || mensagens[0].topico != dominio.TopicoGerarRecomendacao {
+		t.Fatalf("mensagens = %+v, esperado um repasse ao worker", mensagens)
+	}
+	if _, err := servico.ProcessarSolicitacao(mensagens[0].payload.(dominio.SolicitacaoGeracao)); err != nil {
+		t.Fatalf("erro no worker: %v", err)
+	}
+	if status := repo.statusJob(job.ID); status != dominio.JobConcluido || len(repo.recomendacoes) != 1 {
+		t.Errorf("status = %s, gravadas = %d; esperado concluído com uma recomendação", status, len(repo.recomendacoes))
+	}
+}
+
+func TestExecutarComPrazoReivindicadoGravaUmaVez(t *testing.T) {
+	servico, repo, publicador := novoServicoComPrazo()
+	ctx, cancel := context.WithCancel(context.Background())
+	// a geração síncrona reivindica o job e o prazo acaba durante a gravação
+	liberar := make(chan struct{})
+	repo.aoSalvar = func() {
+		cancel()
+		<-liberar
+	}
+
+	_, job, err := servico.ExecutarComPrazo(ctx, "c1", dominio.FiltroItens{})
+	if !errors.Is(err, dominio.ErrPrazoExcedido) {
+		t.Fatalf("erro = %v, esperado prazo excedido", err)
+	}
+
+	// o worker recebe o repasse, mas o job já tem dono
+	mensagens := publicador.publicadas()
+	if len(mensagens) != 1 {
+		t.Fatalf("mensagens = %+v, esperado um repasse", mensagens)
+	}
+	resultado, err := servico.ProcessarSolicitacao(mensagens[0].payload.(dominio.SolicitacaoGeracao))
+	if err != nil || resultado != nil {
+		t.Fatalf("worker retornou %+v, %v; esperado descarte da mensagem", resultado, err)
+	}
+
+	close(liberar)
+	esperarStatus(t, repo, job.ID, dominio.JobConcluido)
+	if len(repo.recomendacoes) != 1 {
+		t.Errorf("gravadas = %d, esperado 1", len(repo.recomendacoes))
+	}
+}
+
+func TestExecutarComPrazoSemVagaRepassaDireto(t *testing.T) {
+	servico, repo, publicador := novoServicoComPrazo()
+	for range cap(servico.sincronos) {
+		servico.sincronos <- struct{}{}
+	}
+
+	_, job, err := servico.ExecutarComPrazo(context.Background(), "c1", dominio.FiltroItens{})
+	if !errors.Is(err, dominio.ErrPrazoExcedido) {
+		t.Fatalf("erro = %v, esperado prazo excedido", err)
+	}
+	if len(publicador.publicadas()) != 1 || repo.statusJob(job.ID) != dominio.JobPendente {
+		t.Errorf("mensagens = %+v, status = %s; esperado repasse com o job pendente", publicador.publicadas(), repo.statusJob(job.ID))
+	}
+}
+
+func TestExecutarComPrazoClienteInexistente(t *testing.T) {
+	servico, repo, _ := novoServicoComPrazo()
+
+	_, job, err := servico.ExecutarComPrazo(context.Background(), "c9", dominio.FiltroItens{})
+	if !errors.Is(err, dominio.ErrClienteNaoEncontrado) || job != nil || len(repo.jobs) != 0 {
+		t.Errorf("job = %+v, erro = %v; esperado cliente não encontrado sem criar job", job, err)
+	}
+}
+
+func TestSolicitarGeracao(t *testing.T) {
+	servico, repo, publicador := novoServicoComPrazo()
+
+	job, err := servico.SolicitarGeracao("c1", dominio.FiltroItens{Limite: 3})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	mensagens := publicador.publicadas()
+	if len(mensagens) != 1 || mensagens[0].topico != dominio.TopicoGerarRecomendacao {
+		t.Fatalf("mensagens = %+v, esperado uma solicitação", mensagens)
+	}
+	esperado := dominio.SolicitacaoGeracao{ClienteID: "c1", JobID: job.ID, Opcoes: dominio.FiltroItens{Limite: 3}}
+	if sol := mensagens[0].payload.(dominio.SolicitacaoGeracao); sol.ClienteID != esperado.ClienteID || sol.JobID != esperado.JobID || sol.Opcoes.Limite != 3 {
+		t.Errorf("solicitação = %+v, esperado %+v", sol, esperado)
+	}
+	if status := repo.statusJob(job.ID); status != dominio.JobPendente {
+		t.Errorf("status = %s, esperado pendente", status)
+	}
+}
+
+func TestSolicitarGeracaoFilaIndisponivel(t *testing.T) {
+	servico, repo, publicador := novoServicoComPrazo()
+	publicador.falha = errors.New("pubsub fora do ar")
+
+	job, err := servico.SolicitarGeracao("c1", dominio.FiltroItens{})
+	if !errors.Is(err, dominio.ErrFilaIndisponivel) || job != nil {
+		t.Fatalf("job = %+v, erro = %v; esperado fila indisponível", job, err)
+	}
+	for id, j := range repo.jobs {
+		if j.Status != dominio.JobFalhou {
+			t.Errorf("job %s com status %s, esperado falhou", id, j.Status)
+		}
+	}
+}
+
+func TestExecutarComPrazoRepasseFalha(t *testing.T) {
+	servico, repo, publicador := novoServicoComPrazo()
+	publicador.falha = errors.New("pubsub fora do ar")
+	for range cap(servico.sincronos) {
+		servico.sincronos <- struct{}{}
+	}
+
+	_, job, err := servico.ExecutarComPrazo(context.Background(), "c1", dominio.FiltroItens{})
+	if !errors.Is(err, dominio.ErrFilaIndisponivel) {
+		t.Fatalf("erro = %v, esperado fila indisponível", err)
+	}
+	if status := repo.statusJob(job.ID); status != dominio.JobFalhou {
+		t.Errorf("status = %s, esperado falhou", status)
+	}
+}
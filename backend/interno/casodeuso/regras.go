@@ -0,0 +1,209 @@
+package casodeuso
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// ContextoPontuacao reúne os dados compartilhados pelas regras durante o cálculo de um cliente
+type ContextoPontuacao struct {
+	Cliente *dominio.Cliente
+	Config  ConfigPontuacao // snapshot usado em todo o cálculo, mesmo se houver recarga no meio
+
+	// DataReferencia é o "agora" do cálculo, base para o decaimento das interações
+	DataReferencia time.Time
+
+	// dados carregados uma única vez por cliente, indexados pelo ID do produto
+	Posicoes   map[string]dominio.Posicao
+	Interacoes map[string][]dominio.Interacao
+	Supressoes map[string]dominio.Supressao // produtos descartados ainda em carência
+
+	// histórico completo, sem janela nem saldo, usado pelas regras da primeira versão (estratégia legado)
+	ProdutosAplicados   map[string]bool // produtos com alguma aplicação, mesmo se já resgatados
+	ProdutosInteragidos map[string]bool // produtos com alguma interação, de qualquer data
+
+	// Exposicao é a composição da carteira atual, calculada a partir das posições
+	Exposicao ExposicaoCarteira
+}
+
+// ExposicaoCarteira é a fração do saldo do cliente em cada tipo de produto e classe de risco
+type ExposicaoCarteira struct {
+	SaldoTotal float64
+	PorTipo    map[string]float64
+	PorRisco   map[dominio.NivelRisco]float64
+}
+
+// CalcularExposicao agrega as posições do cliente por tipo de produto e por risco
+func CalcularExposicao(posicoes map[string]dominio.Posicao) ExposicaoCarteira {
+	exposicao := ExposicaoCarteira{
+		PorTipo:  make(map[string]float64),
+		PorRisco: make(map[dominio.NivelRisco]float64),
+	}
+	for _, p := range posicoes {
+		exposicao.SaldoTotal += p.Saldo
+		exposicao.PorTipo[p.TipoProduto] += p.Saldo
+		exposicao.PorRisco[p.RiscoAssociado] += p.Saldo
+	}
+	if exposicao.SaldoTotal <= 0 {
+		return exposicao
+	}
+	for tipo, saldo := range exposicao.PorTipo {
+		exposicao.PorTipo[tipo] = saldo / exposicao.SaldoTotal
+	}
+	for risco, saldo := range exposicao.PorRisco {
+		exposicao.PorRisco[risco] = saldo / exposicao.SaldoTotal
+	}
+	return exposicao
+}
+
+// ResultadoRegra é a contribuição de uma regra para a pontuação de um produto
+type ResultadoRegra struct {
+	Pontos   float64
+	Motivo   string                 // vazio quando a regra não deve aparecer no motivo do item
+	Entradas map[string]interface{} // valores que levaram a regra a ser aplicada
+}
+
+// RegraPontuacao define uma regra isolada do motor de recomendação
+type RegraPontuacao interface {
+	// ID identifica a regra no registro (ex: "perfil", "rentabilidade")
+	ID() string
+	// Avaliar calcula a contribuição da regra para o produto informado
+	Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error)
+}
+
+type entradaRegra struct {
+	regra      RegraPontuacao
+	ordem      int
+	habilitada bool
+}
+
+// RegistroRegras mantém as regras disponíveis, sua ordem de execução e se estão habilitadas
+type RegistroRegras struct {
+	mu       sync.RWMutex
+	entradas map[string]*entradaRegra
+}
+
+func NovoRegistroRegras() *RegistroRegras {
+	return &RegistroRegras{entradas: make(map[string]*entradaRegra)}
+}
+
+// Registrar adiciona uma regra habilitada ao registro na ordem informada
+func (r *RegistroRegras) Registrar(regra RegraPontuacao, ordem int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, existe := r.entradas[regra.ID()]; existe {
+		return fmt.Errorf("regra já registrada: %s", regra.ID())
+	}
+	r.entradas[regra.ID()] = &entradaRegra{regra: regra, ordem: ordem, habilitada: true}
+	return nil
+}
+
+// Habilitar volta a incluir a regra no cálculo
+func (r *RegistroRegras) Habilitar(id string) error {
+	return r.alterar(id, func(e *entradaRegra) { e.habilitada = true })
+}
+
+// Desabilitar remove a regra do cálculo sem tirá-la do registro
+func (r *RegistroRegras) Desabilitar(id string) error {
+	return r.alterar(id, func(e *entradaRegra) { e.habilitada = false })
+}
+
+// DefinirOrdem altera a posição da regra na execução (menor ordem executa primeiro)
+func (r *RegistroRegras) DefinirOrdem(id string, ordem int) error {
+	return r.alterar(id, func(e *entradaRegra) { e.ordem = ordem })
+}
+
+func (r *RegistroRegras) alterar(id string, fn func(e *entradaRegra)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entrada, existe := r.entradas[id]
+	if !existe {
+		return fmt.Errorf("regra não encontrada: %s", id)
+	}
+	fn(entrada)
+	return nil
+}
+
+// Ativas retorna as regras habilitadas ordenadas para execução
+func (r *RegistroRegras) Ativas() []RegraPontuacao {
+	r.mu.RLock()
+	entradas := make([]*entradaRegra, 0, len(r.entradas))
+	for _, e := range r.entradas {
+		if e.habilitada {
+			entradas = append(entradas, e)
+		}
+	}
+	r.mu.RUnlock()
+
+	// desempata pelo ID para que a ordem seja sempre determinística
+	sort.Slice(entradas, func(i, j int) bool {
+		if entradas[i].ordem != entradas[j].ordem {
+			return entradas[i].ordem < entradas[j].ordem
+		}
+		return entradas[i].regra.ID() < entradas[j].regra.ID()
+	})
+
+	regras := make([]RegraPontuacao, len(entradas))
+	for i, e := range entradas {
+		regras[i] = e.regra
+	}
+	return regras
+}
+
+// RegistroPadrao retorna o registro com as regras de scoring definidas no projeto
+func RegistroPadrao() *RegistroRegras {
+	return registroFixo(
+		RegraPerfilCompativel{},
+		RegraRentabilidade{},
+		RegraAcessibilidade{},
+		RegraDiversificacao{},
+		RegraInteresseRecente{},
+		RegraRentabilidadeLiquida{},
+		RegraConsistencia{},
+		RegraObjetivo{},
+		RegraConcentracao{},
+		RegraSupressao{},
+	)
+}
+
+// RegistroLegado reproduz o motor da primeira versão, usado como grupo de controle nos
+// experimentos: perfil só com o nível exato, posse e interesse por qualquer registro
+// histórico e nenhuma das regras incluídas depois
+func RegistroLegado() *RegistroRegras {
+	return registroFixo(
+		RegraPerfilLegado{},
+		RegraRentabilidade{},
+		RegraAcessibilidade{},
+		RegraDiversificacaoLegado{},
+		RegraInteresseLegado{},
+	)
+}
+
+// registroFixo registra as regras na ordem recebida, de 10 em 10. IDs repetidos são erro de
+// programação, então entram em pânico na inicialização em vez de sumirem do cálculo.
+func registroFixo(regras ...RegraPontuacao) *RegistroRegras {
+	registro := NovoRegistroRegras()
+	for i, regra := range regras {
+		if err := registro.Registrar(regra, (i+1)*10); err != nil {
+			panic(err)
+		}
+	}
+	return registro
+}
+
+// precisaHistorico indica se alguma regra lê ProdutosAplicados ou ProdutosInteragidos,
+// para que o histórico completo só seja consultado quando for usado
+func precisaHistorico(regras []RegraPontuacao) bool {
+	for _, regra := range regras {
+		if _, ok := regra.(regraComHistorico); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package casodeuso
+
+import "backend/interno/dominio"
+
+// regraComHistorico marca as regras que dependem do histórico completo do cliente
+type regraComHistorico interface {
+	usaHistorico()
+}
+
+// RegraPerfilLegado pontua apenas o nível de risco exatamente adequado ao perfil, sem o
+// crédito parcial da matriz de compatibilidade
+type RegraPerfilLegado struct{}
+
+func (RegraPerfilLegado) ID() string { return "perfil" }
+
+func (RegraPerfilLegado) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	perfil := ctx.Cliente.PerfilRisco
+	if !perfil.Valido() || prod.RiscoAssociado != perfil.RiscoMaximo() {
+		return ResultadoRegra{}, nil
+	}
+	return ResultadoRegra{
+		Pontos: ctx.Config.Pesos.PerfilCompativel[perfil],
+		Motivo: "perfil compativel",
+		Entradas: map[string]interface{}{
+			"perfil_risco":    perfil.String(),
+			"risco_associado": prod.RiscoAssociado.String(),
+		},
+	}, nil
+}
+
+// RegraDiversificacaoLegado penaliza produtos que o cliente já aplicou alguma vez, mesmo
+// que a posição tenha sido resgatada
+type RegraDiversificacaoLegado struct{}
+
+func (RegraDiversificacaoLegado) ID() string { return "diversificacao" }
+
+func (RegraDiversificacaoLegado) usaHistorico() {}
+
+func (RegraDiversificacaoLegado) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	if ctx.ProdutosAplicados[prod.ID] {
+		return ResultadoRegra{Pontos: ctx.Config.Pesos.Diversificacao}, nil
+	}
+	return ResultadoRegra{}, nil
+}
+
+// RegraInteresseLegado soma os pontos cheios de interesse a qualquer produto com interação,
+// sem janela, peso por tipo ou decaimento
+type RegraInteresseLegado struct{}
+
+func (RegraInteresseLegado) ID() string { return "interesse" }
+
+func (RegraInteresseLegado) usaHistorico() {}
+
+func (RegraInteresseLegado) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	if ctx.ProdutosInteragidos[prod.ID] {
+		return ResultadoRegra{Pontos: ctx.Config.Pesos.Interesse, Motivo: "interesse recente"}, nil
+	}
+	return ResultadoRegra{}, nil
+}
@@ -0,0 +1,39 @@
+package casodeuso
+
+import (
+	"testing"
+
+	"backend/interno/dominio"
+)
+
+func TestRegraPerfilLegado(t *testing.T) {
+	executarCasosRegra(t, RegraPerfilLegado{}, []casoRegra{
+		{nome: "nível ideal recebe pontos cheios", cliente: moderado,
+			produto: dominio.Produto{RiscoAssociado: dominio.RiscoMedio}, pontos: 0.25, motivo: "perfil compativel"},
+		{nome: "nível adjacente não pontua", cliente: moderado,
+			produto: dominio.Produto{RiscoAssociado: dominio.RiscoAlto}},
+		{nome: "perfil indefinido não pontua", cliente: dominio.Cliente{ID: "c9"},
+			produto: dominio.Produto{RiscoAssociado: dominio.RiscoBaixo}},
+	})
+}
+
+func TestRegraDiversificacaoLegado(t *testing.T) {
+	executarCasosRegra(t, RegraDiversificacaoLegado{}, []casoRegra{
+		{nome: "produto em carteira é penalizado", cliente: moderado, produto: dominio.Produto{ID: "p1"},
+			posicoes: []dominio.Posicao{{ProdutoID: "p1", Saldo: 1000}}, pontos: -0.2},
+		{nome: "produto já resgatado continua penalizado", cliente: moderado, produto: dominio.Produto{ID: "p1"},
+			aplicados: []string{"p1"}, pontos: -0.2},
+		{nome: "produto nunca aplicado", cliente: moderado, produto: dominio.Produto{ID: "p2"},
+			aplicados: []string{"p1"}},
+	})
+}
+
+func TestRegraInteresseLegado(t *testing.T) {
+	executarCasosRegra(t, RegraInteresseLegado{}, []casoRegra{
+		{nome: "interação antiga recebe pontos cheios", cliente: moderado, produto: dominio.Produto{ID: "p1"},
+			interacoes: []dominio.Interacao{{ProdutoID: "p1", Tipo: "visualizacao", Data: diasAtras(400)}},
+			pontos:     0.15, motivo: "interesse recente"},
+		{nome: "sem interação", cliente: moderado, produto: dominio.Produto{ID: "p2"},
+			interacoes: []dominio.Interacao{{ProdutoID: "p1", Data: diasAtras(1)}}},
+	})
+}
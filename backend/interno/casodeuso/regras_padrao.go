@@ -0,0 +1,334 @@
+package casodeuso
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// RegraPerfilCompativel pontua o risco do produto frente ao perfil do cliente usando a matriz
+// de compatibilidade: o nível ideal recebe os pontos cheios e níveis adjacentes, crédito parcial
+type RegraPerfilCompativel struct{}
+
+func (RegraPerfilCompativel) ID() string { return "perfil" }
+
+func (RegraPerfilCompativel) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	perfil := ctx.Cliente.PerfilRisco
+	fator := ctx.Config.MatrizCompatibilidade[perfil][prod.RiscoAssociado]
+	if fator <= 0 {
+		return ResultadoRegra{}, nil
+	}
+
+	motivo := "perfil compativel"
+	if fator < 1 {
+		motivo = "perfil parcialmente compativel"
+	}
+
+	return ResultadoRegra{
+		Pontos: ctx.Config.Pesos.PerfilCompativel[perfil] * fator,
+		Motivo: motivo,
+		Entradas: map[string]interface{}{
+			"perfil_risco":          perfil.String(),
+			"risco_associado":       prod.RiscoAssociado.String(),
+			"fator_compatibilidade": fator,
+		},
+	}, nil
+}
+
+// RegraRentabilidade pontua produtos com rentabilidade em 12 meses acima do limiar configurado
+type RegraRentabilidade struct{}
+
+func (RegraRentabilidade) ID() string { return "rentabilidade" }
+
+func (RegraRentabilidade) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	if prod.Rentabilidade12m > ctx.Config.Limiares.RentabilidadeMinima12m {
+		return ResultadoRegra{
+			Pontos: ctx.Config.Pesos.Rentabilidade,
+			Motivo: "boa rentabilidade",
+			Entradas: map[string]interface{}{
+				"rentabilidade_12m": prod.Rentabilidade12m,
+				"limiar":            ctx.Config.Limiares.RentabilidadeMinima12m,
+			},
+		}, nil
+	}
+	return ResultadoRegra{}, nil
+}
+
+// RegraAcessibilidade pontua produtos cuja aplicação mínima cabe na fração configurada do patrimônio
+type RegraAcessibilidade struct{}
+
+func (RegraAcessibilidade) ID() string { return "acessibilidade" }
+
+func (RegraAcessibilidade) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	patrimonio := ctx.Cliente.Patrimonio
+	limite := patrimonio * ctx.Config.Limiares.AcessibilidadePatrimonio
+	if patrimonio > 0 && prod.AplicacaoMinima < limite {
+		return ResultadoRegra{
+			Pontos: ctx.Config.Pesos.Acessibilidade,
+			Motivo: "acessivel",
+			Entradas: map[string]interface{}{
+				"aplicacao_minima": prod.AplicacaoMinima,
+				"patrimonio":       patrimonio,
+				"limite":           limite,
+			},
+		}, nil
+	}
+	return ResultadoRegra{}, nil
+}
+
+// RegraDiversificacao penaliza produtos em que o cliente ainda tem saldo
+type RegraDiversificacao struct{}
+
+func (RegraDiversificacao) ID() string { return "diversificacao" }
+
+func (RegraDiversificacao) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	if posicao, ok := ctx.Posicoes[prod.ID]; ok {
+		return ResultadoRegra{
+			Pontos:   ctx.Config.Pesos.Diversificacao,
+			Entradas: map[string]interface{}{"saldo_atual": posicao.Saldo},
+		}, nil
+	}
+	return ResultadoRegra{}, nil
+}
+
+// RegraInteresseRecente pontua produtos com interações recentes, ponderadas por tipo,
+// duração e idade (decaimento exponencial pela meia-vida configurada)
+type RegraInteresseRecente struct{}
+
+func (RegraInteresseRecente) ID() string { return "interesse" }
+
+func (RegraInteresseRecente) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	interacoes := ctx.Interacoes[prod.ID]
+	if len(interacoes) == 0 {
+		return ResultadoRegra{}, nil
+	}
+
+	cfg := ctx.Config.Interesse
+	sinal := 0.0
+	var ultima time.Time
+	for _, i := range interacoes {
+		idadeDias := ctx.DataReferencia.Sub(i.Data).Hours() / 24
+		if idadeDias < 0 || idadeDias > float64(cfg.JanelaDias) {
+			continue
+		}
+
+		// interações curtas valem pelo menos metade; a partir da duração de referência valem integralmente
+		fatorDuracao := 1.0
+		if cfg.DuracaoReferenciaSegundos > 0 {
+			fatorDuracao = 0.5 + 0.5*math.Min(float64(i.DuracaoSegundos)/float64(cfg.DuracaoReferenciaSegundos), 1)
+		}
+
+		decaimento := math.Pow(0.5, idadeDias/cfg.MeiaVidaDias)
+		sinal += cfg.PesosTipo[i.Tipo] * fatorDuracao * decaimento
+
+		if i.Data.After(ultima) {
+			ultima = i.Data
+		}
+	}
+
+	intensidade := math.Min(sinal, 1)
+	if intensidade <= 0 {
+		return ResultadoRegra{}, nil
+	}
+
+	return ResultadoRegra{
+		Pontos: ctx.Config.Pesos.Interesse * intensidade,
+		Motivo: "interesse recente",
+		Entradas: map[string]interface{}{
+			"interacoes":       len(interacoes),
+			"intensidade":      intensidade,
+			"ultima_interacao": ultima,
+			"meia_vida_dias":   cfg.MeiaVidaDias,
+		},
+	}, nil
+}
+
+// RegraRentabilidadeLiquida pontua o retorno em 12 meses descontada a taxa de administração.
+// A curva é crescente e saturada, então produtos com a mesma rentabilidade bruta e taxas
+// diferentes nunca empatam.
+type RegraRentabilidadeLiquida struct{}
+
+func (RegraRentabilidadeLiquida) ID() string { return "rentabilidade_liquida" }
+
+func (RegraRentabilidadeLiquida) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	liquida := prod.Rentabilidade12m - prod.TaxaAdministracao
+	if liquida <= 0 {
+		return ResultadoRegra{}, nil
+	}
+
+	escala := ctx.Config.Limiares.EscalaRentabilidadeLiquida
+	return ResultadoRegra{
+		Pontos: ctx.Config.Pesos.RentabilidadeLiquida * (1 - math.Exp(-liquida/escala)),
+		Motivo: "rentabilidade liquida",
+		Entradas: map[string]interface{}{
+			"rentabilidade_12m":     prod.Rentabilidade12m,
+			"taxa_administracao":    prod.TaxaAdministracao,
+			"rentabilidade_liquida": liquida,
+		},
+	}, nil
+}
+
+// RegraConsistencia pontua produtos cujo retorno anualizado em 36 meses é próximo do
+// retorno em 12 meses, favorecendo desempenho sustentado em vez de um ano isolado
+type RegraConsistencia struct{}
+
+func (RegraConsistencia) ID() string { return "consistencia" }
+
+func (RegraConsistencia) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	if prod.Rentabilidade12m <= 0 || prod.Rentabilidade36m <= 0 {
+		return ResultadoRegra{}, nil
+	}
+
+	// rentabilidade_36m é acumulada; converte para taxa anual equivalente
+	anualizada36m := (math.Pow(1+prod.Rentabilidade36m/100, 1.0/3) - 1) * 100
+	consistencia := math.Min(anualizada36m, prod.Rentabilidade12m) / math.Max(anualizada36m, prod.Rentabilidade12m)
+
+	return ResultadoRegra{
+		Pontos: ctx.Config.Pesos.Consistencia * consistencia,
+		Motivo: "retorno consistente",
+		Entradas: map[string]interface{}{
+			"rentabilidade_12m":       prod.Rentabilidade12m,
+			"rentabilidade_36m":       prod.Rentabilidade36m,
+			"rentabilidade_36m_anual": anualizada36m,
+			"indice_consistencia":     consistencia,
+		},
+	}, nil
+}
+
+// horizonteLongoMeses é o horizonte a partir do qual o objetivo prefere integralmente os
+// produtos de liquidez mais longa dentro do prazo aceito
+const horizonteLongoMeses = 120
+
+// RegraObjetivo pontua a aderência do produto ao objetivo de investimento do cliente:
+// metade pela liquidez, metade pelo tipo de produto preferido. A liquidez dentro do prazo
+// aceito recebe metade do crédito; a outra metade depende do encaixe no horizonte, já que
+// objetivos longos não precisam de resgate imediato e favorecem produtos de prazo maior.
+type RegraObjetivo struct{}
+
+func (RegraObjetivo) ID() string { return "objetivo" }
+
+func (RegraObjetivo) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	objetivo, ok := ctx.Config.ObjetivoDoCliente(ctx.Cliente.ObjetivoInvestimento)
+	if !ok {
+		return ResultadoRegra{}, nil
+	}
+
+	aderencia := 0.0
+	dias, liquidezConhecida := prod.DiasLiquidez()
+	liquidezAdequada := liquidezConhecida && dias <= objetivo.LiquidezMaximaDias
+	ajusteHorizonte := 0.0
+	if liquidezAdequada {
+		ajusteHorizonte = ajusteAoHorizonte(dias, objetivo)
+		aderencia += 0.25 + 0.25*ajusteHorizonte
+	}
+
+	tipoPreferido := slices.Contains(objetivo.TiposPreferidos, prod.TipoProduto)
+	if tipoPreferido {
+		aderencia += 0.5
+	}
+
+	if aderencia == 0 {
+		return ResultadoRegra{}, nil
+	}
+
+	return ResultadoRegra{
+		Pontos: ctx.Config.Pesos.Objetivo * aderencia,
+		Motivo: "aderente ao objetivo",
+		Entradas: map[string]interface{}{
+			"objetivo_investimento": ctx.Cliente.ObjetivoInvestimento,
+			"horizonte_meses":       objetivo.HorizonteMeses,
+			"liquidez":              prod.Liquidez,
+			"liquidez_maxima_dias":  objetivo.LiquidezMaximaDias,
+			"liquidez_adequada":     liquidezAdequada,
+			"ajuste_horizonte":      ajusteHorizonte,
+			"tipo_preferido":        tipoPreferido,
+		},
+	}, nil
+}
+
+// ajusteAoHorizonte vai de 0 a 1. Em horizontes curtos qualquer liquidez aceita vale 1; conforme
+// o horizonte se aproxima de horizonteLongoMeses, o valor passa a crescer com os dias de liquidez
+// e só o produto no limite do prazo aceito recebe 1.
+func ajusteAoHorizonte(dias int, objetivo ConfigObjetivo) float64 {
+	if objetivo.LiquidezMaximaDias <= 0 {
+		return 1
+	}
+	peso := math.Min(1, float64(objetivo.HorizonteMeses)/horizonteLongoMeses)
+	fracaoPrazo := float64(dias) / float64(objetivo.LiquidezMaximaDias)
+	return 1 - peso + peso*fracaoPrazo
+}
+
+// RegraConcentracao olha a composição atual da carteira: produtos de um tipo ou classe de
+// risco em que o cliente ainda não tem exposição são bonificados, e os que aprofundariam
+// uma concentração acima do limiar são penalizados proporcionalmente ao excesso
+type RegraConcentracao struct{}
+
+func (RegraConcentracao) ID() string { return "concentracao" }
+
+func (RegraConcentracao) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	exposicao := ctx.Exposicao
+	if exposicao.SaldoTotal <= 0 {
+		return ResultadoRegra{}, nil
+	}
+
+	limiar := ctx.Config.Limiares.ConcentracaoMaxima
+	fracaoTipo := exposicao.PorTipo[prod.TipoProduto]
+	fracaoRisco := exposicao.PorRisco[prod.RiscoAssociado]
+
+	// cada dimensão vai de -1 (carteira toda concentrada) a +1 (lacuna)
+	fator := (fatorConcentracao(fracaoTipo, limiar) + fatorConcentracao(fracaoRisco, limiar)) / 2
+	if fator == 0 {
+		return ResultadoRegra{}, nil
+	}
+
+	motivo := ""
+	if fator > 0 {
+		motivo = "diversifica a carteira"
+	}
+
+	return ResultadoRegra{
+		Pontos: ctx.Config.Pesos.Concentracao * fator,
+		Motivo: motivo,
+		Entradas: map[string]interface{}{
+			"exposicao_tipo":      fracaoTipo,
+			"exposicao_risco":     fracaoRisco,
+			"concentracao_maxima": limiar,
+		},
+	}, nil
+}
+
+func fatorConcentracao(fracao, limiar float64) float64 {
+	if fracao == 0 {
+		return 1
+	}
+	if fracao > limiar {
+		return -(fracao - limiar) / (1 - limiar)
+	}
+	return 0
+}
+
+// RegraSupressao penaliza produtos descartados pelo cliente enquanto durar a carência,
+// quando a configuração usa o modo "penalizar"
+type RegraSupressao struct{}
+
+func (RegraSupressao) ID() string { return "supressao" }
+
+func (RegraSupressao) Avaliar(ctx *ContextoPontuacao, prod dominio.Produto) (ResultadoRegra, error) {
+	if ctx.Config.Supressao.Modo != ModoSupressaoPenalizar {
+		return ResultadoRegra{}, nil
+	}
+	supressao, ok := ctx.Supressoes[prod.ID]
+	if !ok {
+		return ResultadoRegra{}, nil
+	}
+	return ResultadoRegra{
+		Pontos: ctx.Config.Pesos.Supressao,
+		Motivo: "descartado recentemente",
+		Entradas: map[string]interface{}{
+			"descartado_em": supressao.Inicio.Format(time.DateOnly),
+			"expira_em":     supressao.ExpiraEm.Format(time.DateOnly),
+		},
+	}, nil
+}
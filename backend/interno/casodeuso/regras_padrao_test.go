@@ -0,0 +1,231 @@
+package casodeuso
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// agoraTeste fixa o "agora" do cálculo para que o decaimento seja reprodutível
+var agoraTeste = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// casoRegra descreve um cenário isolado: o contexto é montado só com o que o caso informa
+type casoRegra struct {
+	nome       string
+	cliente    dominio.Cliente
+	produto    dominio.Produto
+	posicoes   []dominio.Posicao
+	interacoes []dominio.Interacao
+	supressoes map[string]dominio.Supressao
+	aplicados  []string // histórico além das posições, para as regras legado
+	ajustar    func(cfg *ConfigPontuacao)
+	pontos     float64
+	motivo     string
+}
+
+func (c casoRegra) contexto() *ContextoPontuacao {
+	cfg := ConfigPadrao()
+	if c.ajustar != nil {
+		c.ajustar(&cfg)
+	}
+	cliente := c.cliente
+	ctx := novoContextoPontuacao(&cliente, cfg, agoraTeste, c.posicoes, c.interacoes)
+	ctx.Supressoes = c.supressoes
+	for _, produtoID := range c.aplicados {
+		ctx.ProdutosAplicados[produtoID] = true
+	}
+	return ctx
+}
+
+func executarCasosRegra(t *testing.T, regra RegraPontuacao, casos []casoRegra) {
+	t.Helper()
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			res, err := regra.Avaliar(c.contexto(), c.produto)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !quaseIgual(res.Pontos, c.pontos) {
+				t.Errorf("pontos = %v, esperado %v", res.Pontos, c.pontos)
+			}
+			if res.Motivo != c.motivo {
+				t.Errorf("motivo = %q, esperado %q", res.Motivo, c.motivo)
+			}
+		})
+	}
+}
+
+func diasAtras(dias float64) time.Time {
+	return agoraTeste.Add(-time.Duration(dias * 24 * float64(time.Hour)))
+}
+
+var (
+	moderado      = dominio.Cliente{ID: "c1", PerfilRisco: dominio.PerfilModerado, Patrimonio: 100000}
+	conservador   = dominio.Cliente{ID: "c2", PerfilRisco: dominio.PerfilConservador, Patrimonio: 100000}
+	aposentadoria = dominio.Cliente{ID: "c3", PerfilRisco: dominio.PerfilArrojado, Patrimonio: 100000, ObjetivoInvestimento: "Aposentadoria"}
+)
+
+func TestRegraPerfilCompativel(t *testing.T) {
+	executarCasosRegra(t, RegraPerfilCompativel{}, []casoRegra{
+		{nome: "nível ideal recebe pontos cheios", cliente: moderado,
+			produto: dominio.Produto{RiscoAssociado: dominio.RiscoMedio}, pontos: 0.25, motivo: "perfil compativel"},
+		{nome: "nível adjacente recebe crédito parcial", cliente: moderado,
+			produto: dominio.Produto{RiscoAssociado: dominio.RiscoAlto}, pontos: 0.25 * 0.3, motivo: "perfil parcialmente compativel"},
+		{nome: "fator zero não pontua", cliente: conservador,
+			produto: dominio.Produto{RiscoAssociado: dominio.RiscoAlto}},
+	})
+}
+
+func TestRegraRentabilidade(t *testing.T) {
+	executarCasosRegra(t, RegraRentabilidade{}, []casoRegra{
+		{nome: "acima do limiar", cliente: moderado, produto: dominio.Produto{Rentabilidade12m: 12}, pontos: 0.1, motivo: "boa rentabilidade"},
+		{nome: "no limiar não pontua", cliente: moderado, produto: dominio.Produto{Rentabilidade12m: 10}},
+	})
+}
+
+func TestRegraAcessibilidade(t *testing.T) {
+	executarCasosRegra(t, RegraAcessibilidade{}, []casoRegra{
+		{nome: "mínimo abaixo da fração do patrimônio", cliente: moderado, produto: dominio.Produto{AplicacaoMinima: 1000}, pontos: 0.1, motivo: "acessivel"},
+		{nome: "mínimo igual ao limite não pontua", cliente: moderado, produto: dominio.Produto{AplicacaoMinima: 5000}},
+		{nome: "cliente sem patrimônio não pontua", cliente: dominio.Cliente{PerfilRisco: dominio.PerfilModerado}, produto: dominio.Produto{}},
+	})
+}
+
+func TestRegraDiversificacao(t *testing.T) {
+	executarCasosRegra(t, RegraDiversificacao{}, []casoRegra{
+		{nome: "produto em carteira é penalizado", cliente: moderado, produto: dominio.Produto{ID: "p1"},
+			posicoes: []dominio.Posicao{{ProdutoID: "p1", Saldo: 500}}, pontos: -0.2},
+		{nome: "produto novo não é penalizado", cliente: moderado, produto: dominio.Produto{ID: "p2"},
+			posicoes: []dominio.Posicao{{ProdutoID: "p1", Saldo: 500}}},
+	})
+}
+
+func TestRegraInteresseRecente(t *testing.T) {
+	produto := dominio.Produto{ID: "p1"}
+	interacao := func(tipo string, idadeDias float64, duracao int) dominio.Interacao {
+		return dominio.Interacao{ProdutoID: "p1", Tipo: tipo, Data: diasAtras(idadeDias), DuracaoSegundos: duracao}
+	}
+
+	executarCasosRegra(t, RegraInteresseRecente{}, []casoRegra{
+		{nome: "sem interações", cliente: moderado, produto: produto},
+		{nome: "interação de hoje com duração de referência vale integralmente", cliente: moderado, produto: produto,
+			interacoes: []dominio.Interacao{interacao("Simulacao", 0, 120)}, pontos: 0.15, motivo: "interesse recente"},
+		{nome: "interação com a idade da meia-vida vale metade", cliente: moderado, produto: produto,
+			interacoes: []dominio.Interacao{interacao("Simulacao", 30, 120)}, pontos: 0.15 * 0.5, motivo: "interesse recente"},
+		{nome: "duas meias-vidas valem um quarto", cliente: moderado, produto: produto,
+			interacoes: []dominio.Interacao{interacao("Simulacao", 60, 120)}, pontos: 0.15 * 0.25, motivo: "interesse recente"},
+		{nome: "interação sem duração vale metade do peso do tipo", cliente: moderado, produto: produto,
+			interacoes: []dominio.Interacao{interacao("Clique", 0, 0)}, pontos: 0.15 * 0.5 * 0.5, motivo: "interesse recente"},
+		{nome: "soma das interações é limitada a 1", cliente: moderado, produto: produto,
+			interacoes: []dominio.Interacao{interacao("Simulacao", 0, 120), interacao("Favorito", 0, 120)}, pontos: 0.15, motivo: "interesse recente"},
+		{nome: "interação fora da janela é ignorada", cliente: moderado, produto: produto,
+			interacoes: []dominio.Interacao{interacao("Simulacao", 200, 120)}},
+		{nome: "tipo sem peso é ignorado", cliente: moderado, produto: produto,
+			interacoes: []dominio.Interacao{interacao("Compartilhamento", 0, 120)}},
+	})
+}
+
+func TestRegraRentabilidadeLiquida(t *testing.T) {
+	executarCasosRegra(t, RegraRentabilidadeLiquida{}, []casoRegra{
+		{nome: "retorno líquido igual à escala rende 1-1/e dos pontos", cliente: moderado,
+			produto: dominio.Produto{Rentabilidade12m: 12, TaxaAdministracao: 2}, pontos: 0.1 * (1 - math.Exp(-1)), motivo: "rentabilidade liquida"},
+		{nome: "taxa maior que o retorno não pontua", cliente: moderado,
+			produto: dominio.Produto{Rentabilidade12m: 1, TaxaAdministracao: 2}},
+	})
+}
+
+func TestRegraConsistencia(t *testing.T) {
+	executarCasosRegra(t, RegraConsistencia{}, []casoRegra{
+		{nome: "36 meses anualizado igual aos 12 meses", cliente: moderado,
+			produto: dominio.Produto{Rentabilidade12m: 10, Rentabilidade36m: 33.1}, pontos: 0.05, motivo: "retorno consistente"},
+		{nome: "12 meses no dobro do histórico vale metade", cliente: moderado,
+			produto: dominio.Produto{Rentabilidade12m: 20, Rentabilidade36m: 33.1}, pontos: 0.025, motivo: "retorno consistente"},
+		{nome: "sem histórico de 36 meses não pontua", cliente: moderado,
+			produto: dominio.Produto{Rentabilidade12m: 10}},
+	})
+}
+
+func TestRegraObjetivo(t *testing.T) {
+	comObjetivo := func(objetivo string) dominio.Cliente {
+		c := moderado
+		c.ObjetivoInvestimento = objetivo
+		return c
+	}
+
+	executarCasosRegra(t, RegraObjetivo{}, []casoRegra{
+		{nome: "horizonte longo: liquidez no limite e tipo preferido", cliente: aposentadoria,
+			produto: dominio.Produto{TipoProduto: "Previdência", Liquidez: "D+60"}, pontos: 0.15, motivo: "aderente ao objetivo"},
+		{nome: "horizonte longo: liquidez imediata recebe só o crédito base", cliente: aposentadoria,
+			produto: dominio.Produto{TipoProduto: "CDB", Liquidez: "D+0"}, pontos: 0.15 * 0.25, motivo: "aderente ao objetivo"},
+		{nome: "horizonte longo: liquidez intermediária recebe crédito proporcional", cliente: aposentadoria,
+			produto: dominio.Produto{TipoProduto: "CDB", Liquidez: "D+30"}, pontos: 0.15 * (0.25 + 0.25*0.5), motivo: "aderente ao objetivo"},
+		{nome: "liquidez acima do prazo aceito só vale pelo tipo", cliente: aposentadoria,
+			produto: dominio.Produto{TipoProduto: "Fundo de Ações", Liquidez: "D+90"}, pontos: 0.15 * 0.5, motivo: "aderente ao objetivo"},
+		{nome: "sem liquidez nem tipo aderentes não pontua", cliente: aposentadoria,
+			produto: dominio.Produto{TipoProduto: "CDB", Liquidez: "D+90"}},
+		{nome: "prazo aceito zero: liquidez imediata vale integralmente", cliente: comObjetivo("Reserva de Emergência"),
+			produto: dominio.Produto{TipoProduto: "CDB", Liquidez: "D+0"}, pontos: 0.15, motivo: "aderente ao objetivo"},
+		{nome: "horizonte médio suaviza a preferência por prazo", cliente: comObjetivo("Compra de Imóvel"),
+			produto: dominio.Produto{TipoProduto: "LCI/LCA", Liquidez: "D+0"}, pontos: 0.15 * (0.25 + 0.25*0.7 + 0.5), motivo: "aderente ao objetivo"},
+		{nome: "objetivo sem configuração não pontua", cliente: comObjetivo("Outro"),
+			produto: dominio.Produto{TipoProduto: "CDB", Liquidez: "D+0"}},
+	})
+}
+
+func TestRegraConcentracao(t *testing.T) {
+	concentrada := []dominio.Posicao{{ProdutoID: "p1", TipoProduto: "CDB", RiscoAssociado: dominio.RiscoBaixo, Saldo: 1000}}
+	dividida := []dominio.Posicao{
+		{ProdutoID: "p1", TipoProduto: "CDB", RiscoAssociado: dominio.RiscoBaixo, Saldo: 500},
+		{ProdutoID: "p2", TipoProduto: "Fundo de Ações", RiscoAssociado: dominio.RiscoAlto, Saldo: 500},
+	}
+
+	executarCasosRegra(t, RegraConcentracao{}, []casoRegra{
+		{nome: "carteira vazia não pontua", cliente: moderado, produto: dominio.Produto{TipoProduto: "CDB"}},
+		{nome: "lacuna de tipo e risco recebe o bônus máximo", cliente: moderado, posicoes: concentrada,
+			produto: dominio.Produto{TipoProduto: "Fundo de Ações", RiscoAssociado: dominio.RiscoAlto}, pontos: 0.15, motivo: "diversifica a carteira"},
+		{nome: "carteira toda concentrada recebe a penalidade máxima", cliente: moderado, posicoes: concentrada,
+			produto: dominio.Produto{TipoProduto: "CDB", RiscoAssociado: dominio.RiscoBaixo}, pontos: -0.15},
+		{nome: "excesso sobre o limiar é penalizado proporcionalmente", cliente: moderado, posicoes: dividida,
+			produto: dominio.Produto{TipoProduto: "CDB", RiscoAssociado: dominio.RiscoAlto}, pontos: 0.15 * -(0.1 / 0.6)},
+		{nome: "lacuna em uma dimensão compensa concentração na outra", cliente: moderado, posicoes: dividida,
+			produto: dominio.Produto{TipoProduto: "CDB", RiscoAssociado: dominio.RiscoMedio}, pontos: 0.15 * (1 - 0.1/0.6) / 2, motivo: "diversifica a carteira"},
+	})
+}
+
+func TestRegraSupressao(t *testing.T) {
+	supressoes := map[string]dominio.Supressao{"p1": {ProdutoID: "p1", Inicio: diasAtras(5), ExpiraEm: agoraTeste.AddDate(0, 0, 25)}}
+	excluir := func(cfg *ConfigPontuacao) { cfg.Supressao.Modo = ModoSupressaoExcluir }
+
+	executarCasosRegra(t, RegraSupressao{}, []casoRegra{
+		{nome: "produto em carência é penalizado", cliente: moderado, produto: dominio.Produto{ID: "p1"},
+			supressoes: supressoes, pontos: -0.5, motivo: "descartado recentemente"},
+		{nome: "produto sem supressão não é penalizado", cliente: moderado, produto: dominio.Produto{ID: "p2"}, supressoes: supressoes},
+		{nome: "modo excluir deixa a supressão para o filtro", cliente: moderado, produto: dominio.Produto{ID: "p1"},
+			supressoes: supressoes, ajustar: excluir},
+	})
+}
+
+func TestAjusteAoHorizonte(t *testing.T) {
+	casos := []struct {
+		nome     string
+		dias     int
+		objetivo ConfigObjetivo
+		esperado float64
+	}{
+		{"horizonte curto ignora o prazo", 0, ConfigObjetivo{HorizonteMeses: 0, LiquidezMaximaDias: 30}, 1},
+		{"horizonte longo e liquidez imediata", 0, ConfigObjetivo{HorizonteMeses: 240, LiquidezMaximaDias: 60}, 0},
+		{"horizonte longo e liquidez no limite", 60, ConfigObjetivo{HorizonteMeses: 240, LiquidezMaximaDias: 60}, 1},
+		{"metade do horizonte longo", 0, ConfigObjetivo{HorizonteMeses: 60, LiquidezMaximaDias: 30}, 0.5},
+		{"prazo aceito zero", 0, ConfigObjetivo{HorizonteMeses: 240, LiquidezMaximaDias: 0}, 1},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if ajuste := ajusteAoHorizonte(c.dias, c.objetivo); !quaseIgual(ajuste, c.esperado) {
+				t.Errorf("ajuste = %v, esperado %v", ajuste, c.esperado)
+			}
+		})
+	}
+}
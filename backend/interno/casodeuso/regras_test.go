@@ -0,0 +1,174 @@
+package casodeuso
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"backend/interno/dominio"
+)
+
+// tolerancia para comparar pontuações calculadas em ponto flutuante
+const tolerancia = 1e-9
+
+func quaseIgual(a, b float64) bool {
+	return math.Abs(a-b) < tolerancia
+}
+
+// regraFixa é uma regra de teste que só identifica sua posição na execução
+type regraFixa string
+
+func (r regraFixa) ID() string { return string(r) }
+
+func (regraFixa) Avaliar(*ContextoPontuacao, dominio.Produto) (ResultadoRegra, error) {
+	return ResultadoRegra{}, nil
+}
+
+func idsAtivas(registro *RegistroRegras) []string {
+	var ids []string
+	for _, regra := range registro.Ativas() {
+		ids = append(ids, regra.ID())
+	}
+	return ids
+}
+
+func TestRegistroRegras(t *testing.T) {
+	casos := []struct {
+		nome     string
+		preparar func(r *RegistroRegras) error
+		esperado []string
+	}{
+		{
+			nome:     "ordena pela ordem informada",
+			preparar: func(r *RegistroRegras) error { return nil },
+			esperado: []string{"a", "b", "c"},
+		},
+		{
+			nome:     "desabilitada sai das ativas",
+			preparar: func(r *RegistroRegras) error { return r.Desabilitar("b") },
+			esperado: []string{"a", "c"},
+		},
+		{
+			nome: "habilitar devolve a regra na mesma posição",
+			preparar: func(r *RegistroRegras) error {
+				if err := r.Desabilitar("b"); err != nil {
+					return err
+				}
+				return r.Habilitar("b")
+			},
+			esperado: []string{"a", "b", "c"},
+		},
+		{
+			nome:     "nova ordem move a regra",
+			preparar: func(r *RegistroRegras) error { return r.DefinirOrdem("c", 1) },
+			esperado: []string{"c", "a", "b"},
+		},
+		{
+			nome:     "empate na ordem desempata pelo ID",
+			preparar: func(r *RegistroRegras) error { return r.DefinirOrdem("c", 10) },
+			esperado: []string{"a", "c", "b"},
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			registro := NovoRegistroRegras()
+			registro.Registrar(regraFixa("b"), 20)
+			registro.Registrar(regraFixa("c"), 30)
+			registro.Registrar(regraFixa("a"), 10)
+
+			if err := c.preparar(registro); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if ids := idsAtivas(registro); !slices.Equal(ids, c.esperado) {
+				t.Errorf("ativas = %v, esperado %v", ids, c.esperado)
+			}
+		})
+	}
+}
+
+func TestRegistroRegrasErros(t *testing.T) {
+	registro := NovoRegistroRegras()
+	registro.Registrar(regraFixa("a"), 10)
+
+	if err := registro.Registrar(regraFixa("a"), 20); err == nil {
+		t.Error("registrar ID repetido deveria falhar")
+	}
+	if err := registro.Desabilitar("inexistente"); err == nil {
+		t.Error("desabilitar regra inexistente deveria falhar")
+	}
+	if err := registro.DefinirOrdem("inexistente", 1); err == nil {
+		t.Error("reordenar regra inexistente deveria falhar")
+	}
+}
+
+func TestRegistrosDoProjeto(t *testing.T) {
+	padrao := idsAtivas(RegistroPadrao())
+	esperadoPadrao := []string{"perfil", "rentabilidade", "acessibilidade", "diversificacao", "interesse",
+		"rentabilidade_liquida", "consistencia", "objetivo", "concentracao", "supressao"}
+	if !slices.Equal(padrao, esperadoPadrao) {
+		t.Errorf("regras padrão = %v, esperado %v", padrao, esperadoPadrao)
+	}
+
+	legado := idsAtivas(RegistroLegado())
+	esperadoLegado := []string{"perfil", "rentabilidade", "acessibilidade", "diversificacao", "interesse"}
+	if !slices.Equal(legado, esperadoLegado) {
+		t.Errorf("regras legado = %v, esperado %v", legado, esperadoLegado)
+	}
+}
+
+func TestPrecisaHistorico(t *testing.T) {
+	if precisaHistorico(RegistroPadrao().Ativas()) {
+		t.Error("a estratégia padrão não deveria consultar o histórico completo")
+	}
+	if !precisaHistorico(RegistroLegado().Ativas()) {
+		t.Error("a estratégia legado deveria consultar o histórico completo")
+	}
+}
+
+func TestCalcularExposicao(t *testing.T) {
+	casos := []struct {
+		nome          string
+		posicoes      []dominio.Posicao
+		saldoTotal    float64
+		porTipo       map[string]float64
+		porRiscoBaixo float64
+	}{
+		{
+			nome:     "sem posições",
+			posicoes: nil,
+			porTipo:  map[string]float64{},
+		},
+		{
+			nome: "frações por tipo e risco",
+			posicoes: []dominio.Posicao{
+				{ProdutoID: "p1", TipoProduto: "CDB", RiscoAssociado: dominio.RiscoBaixo, Saldo: 600},
+				{ProdutoID: "p2", TipoProduto: "CDB", RiscoAssociado: dominio.RiscoBaixo, Saldo: 200},
+				{ProdutoID: "p3", TipoProduto: "Fundo de Ações", RiscoAssociado: dominio.RiscoAlto, Saldo: 200},
+			},
+			saldoTotal:    1000,
+			porTipo:       map[string]float64{"CDB": 0.8, "Fundo de Ações": 0.2},
+			porRiscoBaixo: 0.8,
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			exposicao := CalcularExposicao(indexarPosicoesPorProduto(c.posicoes))
+			if !quaseIgual(exposicao.SaldoTotal, c.saldoTotal) {
+				t.Errorf("saldo total = %v, esperado %v", exposicao.SaldoTotal, c.saldoTotal)
+			}
+			if len(exposicao.PorTipo) != len(c.porTipo) {
+				t.Fatalf("tipos = %v, esperado %v", exposicao.PorTipo, c.porTipo)
+			}
+			for tipo, fracao := range c.porTipo {
+				if !quaseIgual(exposicao.PorTipo[tipo], fracao) {
+					t.Errorf("fração de %s = %v, esperado %v", tipo, exposicao.PorTipo[tipo], fracao)
+				}
+			}
+			if !quaseIgual(exposicao.PorRisco[dominio.RiscoBaixo], c.porRiscoBaixo) {
+				t.Errorf("fração de risco baixo = %v, esperado %v", exposicao.PorRisco[dominio.RiscoBaixo], c.porRiscoBaixo)
+			}
+		})
+	}
+}
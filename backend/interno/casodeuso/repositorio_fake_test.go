@@ -0,0 +1,406 @@
+package casodeuso
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// repositorioFake guarda os dados em memória. Os métodos não usados pelos testes caem na
+// interface embutida (nil) e entram em pânico, o que denuncia uma dependência inesperada.
+type repositorioFake struct {
+	dominio.RepositorioDados
+
+	mu            sync.Mutex
+	clientes      map[string]dominio.Cliente
+	produtos      []dominio.Produto
+	recomendacoes []dominio.ResultadoRecomendacao
+	feedback      []dominio.EventoFeedback
+	supressoes    []supressaoFake
+	jobs          map[string]dominio.Job
+	lotes         map[string]loteFake
+
+	consultasCliente int
+	falhaCheckpoint  error // recusa AtualizarPublicacaoLote, como uma queda do banco no meio do lote
+	// ganchos chamados fora do lock, para os testes controlarem o tempo de cada etapa
+	aoListarProdutos func(ctx context.Context) error
+	aoSalvar         func()
+}
+
+// loteFake guarda a reserva de publicação e o resultado por cliente, que não fazem parte da entidade
+type loteFake struct {
+	dominio.Lote
+	reservaAte time.Time
+	resultados map[string]bool
+}
+
+// supressaoFake guarda o encerramento manual, que não faz parte da entidade
+type supressaoFake struct {
+	dominio.Supressao
+	encerrada bool
+}
+
+func novoRepositorioFake(clientes ...dominio.Cliente) *repositorioFake {
+	r := &repositorioFake{clientes: make(map[string]dominio.Cliente), jobs: make(map[string]dominio.Job),
+		lotes: make(map[string]loteFake)}
+	for _, c := range clientes {
+		r.clientes[c.ID] = c
+	}
+	return r
+}
+
+func (r *repositorioFake) ObterCliente(_ context.Context, id string) (*dominio.Cliente, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.consultasCliente++
+	c, existe := r.clientes[id]
+	if !existe {
+		return nil, dominio.ErrClienteNaoEncontrado
+	}
+	return &c, nil
+}
+
+func (r *repositorioFake) ListarProdutosAtivos(ctx context.Context) ([]dominio.Produto, error) {
+	if r.aoListarProdutos != nil {
+		if err := r.aoListarProdutos(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.produtos), nil
+}
+
+func (r *repositorioFake) ListarPosicoes(context.Context, string) ([]dominio.Posicao, error) {
+	return nil, nil
+}
+
+func (r *repositorioFake) ListarInteracoes(context.Context, string, time.Time) ([]dominio.Interacao, error) {
+	return nil, nil
+}
+
+func (r *repositorioFake) ListarHistoricoProdutos(context.Context, string) (map[string]bool, map[string]bool, error) {
+	return nil, nil, nil
+}
+
+func (r *repositorioFake) SalvarRecomendacao(_ context.Context, resultado *dominio.ResultadoRecomendacao) (string, error) {
+	if r.aoSalvar != nil {
+		r.aoSalvar()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	salvo := *resultado
+	salvo.ID = fmt.Sprintf("r%d", len(r.recomendacoes)+1)
+	r.recomendacoes = append(r.recomendacoes, salvo)
+	return salvo.ID, nil
+}
+
+func (r *repositorioFake) BuscarUltimaRecomendacao(clienteID string) (*dominio.ResultadoRecomendacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.recomendacoes) - 1; i >= 0; i-- {
+		if r.recomendacoes[i].ClienteID == clienteID {
+			resultado := r.recomendacoes[i]
+			return &resultado, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *repositorioFake) BuscarRecomendacao(id string) (*dominio.ResultadoRecomendacao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, resultado := range r.recomendacoes {
+		if resultado.ID == id {
+			return &resultado, nil
+		}
+	}
+	return nil, dominio.ErrRecomendacaoNaoEncontrada
+}
+
+func (r *repositorioFake) SalvarFeedback(eventos []dominio.EventoFeedback) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.feedback = append(r.feedback, eventos...)
+	return nil
+}
+
+func (r *repositorioFake) SalvarSupressoes(supressoes []dominio.Supressao) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, sup := range supressoes {
+		r.supressoes = append(r.supressoes, supressaoFake{Supressao: sup})
+	}
+	return nil
+}
+
+func (r *repositorioFake) ListarSupressoesAtivas(_ context.Context, clienteID string, em time.Time) ([]dominio.Supressao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ativas []dominio.Supressao
+	for _, sup := range r.supressoes {
+		if sup.ClienteID == clienteID && !sup.encerrada && sup.ExpiraEm.After(em) {
+			ativas = append(ativas, sup.Supressao)
+		}
+	}
+	return ativas, nil
+}
+
+func (r *repositorioFake) EncerrarSupressoes(clienteID, produtoID, responsavel string, em time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var encerradas int64
+	for i := range r.supressoes {
+		sup := &r.supressoes[i]
+		if sup.ClienteID == clienteID && sup.ProdutoID == produtoID && !sup.encerrada && sup.ExpiraEm.After(em) {
+			sup.encerrada = true
+			encerradas++
+		}
+	}
+	return encerradas, nil
+}
+
+func (r *repositorioFake) CriarJob(_ context.Context, clienteID string) (*dominio.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job := dominio.Job{ID: fmt.Sprintf("job-%d", len(r.jobs)+1), ClienteID: clienteID, Status: dominio.JobPendente}
+	r.jobs[job.ID] = job
+	return &job, nil
+}
+
+func (r *repositorioFake) BuscarJob(id string) (*dominio.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, existe := r.jobs[id]
+	if !existe {
+		return nil, dominio.ErrJobNaoEncontrado
+	}
+	return &job, nil
+}
+
+func (r *repositorioFake) ReivindicarJob(ctx context.Context, id string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, existe := r.jobs[id]
+	if !existe || job.Status != dominio.JobPendente {
+		return false, nil
+	}
+	job.Status = dominio.JobProcessando
+	r.jobs[id] = job
+	return true, nil
+}
+
+func (r *repositorioFake) AtualizarJob(id string, status dominio.StatusJob, recomendacaoID, erro string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job := r.jobs[id]
+	job.Status, job.RecomendacaoID, job.Erro = status, recomendacaoID, erro
+	r.jobs[id] = job
+	return nil
+}
+
+// statusJob lê o status sob o lock, já que a geração síncrona atualiza o job em outra goroutine
+func (r *repositorioFake) statusJob(id string) dominio.StatusJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.jobs[id].Status
+}
+
+// publicadorFake registra as mensagens publicadas; com falha definida, recusa todas
+type publicadorFake struct {
+	mu        sync.Mutex
+	mensagens []mensagemFake
+	falha     error
+}
+
+type mensagemFake struct {
+	topico  string
+	payload interface{}
+}
+
+func (p *publicadorFake) Publicar(topico string, payload interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.falha != nil {
+		return p.falha
+	}
+	p.mensagens = append(p.mensagens, mensagemFake{topico, payload})
+	return nil
+}
+
+func (p *publicadorFake) publicadas() []mensagemFake {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.mensagens)
+}
+
+// ListarClientesApos ignora o segmento: os testes de lote usam apenas clientes do segmento
+func (r *repositorioFake) ListarClientesApos(cursor string, limite int, _ dominio.SegmentoClientes) ([]dominio.Cliente, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := slices.Sorted(maps.Keys(r.clientes))
+	var pagina []dominio.Cliente
+	for _, id := range ids {
+		if id > cursor && len(pagina) < limite {
+			pagina = append(pagina, r.clientes[id])
+		}
+	}
+	return pagina, nil
+}
+
+func (r *repositorioFake) ContarClientes(dominio.SegmentoClientes) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.clientes), nil
+}
+
+func (r *repositorioFake) CriarLote(total int, opcoes dominio.FiltroItens, segmento dominio.SegmentoClientes, reserva time.Duration) (*dominio.Lote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lote := dominio.Lote{ID: fmt.Sprintf("lote-%d", len(r.lotes)+1), Status: dominio.LoteEmAndamento,
+		Opcoes: opcoes, Segmento: segmento, Total: total, CriadoEm: time.Now()}
+	r.lotes[lote.ID] = loteFake{Lote: lote, reservaAte: time.Now().Add(reserva), resultados: make(map[string]bool)}
+	return &lote, nil
+}
+
+func (r *repositorioFake) BuscarLote(id string) (*dominio.Lote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lote, existe := r.lotes[id]
+	if !existe {
+		return nil, dominio.ErrLoteNaoEncontrado
+	}
+	return &lote.Lote, nil
+}
+
+func (r *repositorioFake) AtualizarPublicacaoLote(id string, publicados int, cursor string, reserva time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.falhaCheckpoint != nil {
+		return r.falhaCheckpoint
+	}
+	lote := r.lotes[id]
+	lote.Publicados, lote.Cursor, lote.reservaAte = publicados, cursor, time.Now().Add(reserva)
+	r.lotes[id] = lote
+	return nil
+}
+
+func (r *repositorioFake) LiberarPublicacaoLote(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lote := r.lotes[id]
+	if lote.Status == dominio.LoteEmAndamento && !lote.PublicacaoFinalizada {
+		lote.reservaAte = time.Now()
+		r.lotes[id] = lote
+	}
+	return nil
+}
+
+func (r *repositorioFake) FinalizarPublicacaoLote(id string, publicados int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lote := r.lotes[id]
+	lote.Publicados, lote.Total, lote.PublicacaoFinalizada, lote.reservaAte = publicados, publicados, true, time.Time{}
+	if lote.Status == dominio.LoteEmAndamento && lote.Processados() >= publicados {
+		lote.Status = dominio.LoteConcluido
+	}
+	r.lotes[id] = lote
+	return nil
+}
+
+func (r *repositorioFake) ReivindicarLotesPendentes(reserva time.Duration) ([]dominio.Lote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reivindicados []dominio.Lote
+	for id, lote := range r.lotes {
+		if lote.Status == dominio.LoteEmAndamento && !lote.PublicacaoFinalizada && lote.reservaAte.Before(time.Now()) {
+			lote.reservaAte = time.Now().Add(reserva)
+			r.lotes[id] = lote
+			reivindicados = append(reivindicados, lote.Lote)
+		}
+	}
+	return reivindicados, nil
+}
+
+// RegistrarResultadoLote segue a mesma regra do Postgres: um registro por cliente, com a falha
+// substituída por um sucesso posterior
+func (r *repositorioFake) RegistrarResultadoLote(id, clienteID string, sucesso bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lote := r.lotes[id]
+	anterior, registrado := lote.resultados[clienteID]
+	switch {
+	case !registrado && sucesso:
+		lote.Concluidos++
+	case !registrado:
+		lote.Falhos++
+	case sucesso && !anterior:
+		lote.Concluidos++
+		lote.Falhos--
+	default:
+		return nil
+	}
+	lote.resultados[clienteID] = sucesso
+	if lote.Status == dominio.LoteEmAndamento && lote.PublicacaoFinalizada && lote.Processados() >= lote.Total {
+		lote.Status = dominio.LoteConcluido
+	}
+	r.lotes[id] = lote
+	return nil
+}
+
+func (r *repositorioFake) CancelarLote(id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lote, existe := r.lotes[id]
+	if !existe || lote.Status != dominio.LoteEmAndamento {
+		return false, nil
+	}
+	lote.Status = dominio.LoteCancelado
+	r.lotes[id] = lote
+	return true, nil
+}
+
+// lote lê o lote sob o lock, já que a publicação roda em outra goroutine
+func (r *repositorioFake) lote(id string) loteFake {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lotes[id]
+}
@@ -0,0 +1,70 @@
+package casodeuso
+
+import "backend/interno/dominio"
+
+// Reranquear reordena os itens já ordenados por pontuação usando Maximal Marginal Relevance:
+// a cada passo escolhe o item que melhor equilibra relevância (pontuação normalizada) e
+// diferença em relação aos já escolhidos (tipo de produto e risco). Com o re-ranqueamento
+// desabilitado apenas aplica o corte de top-N.
+func Reranquear(itens []dominio.RecomendacaoItem, cfg ConfigReranqueamento) []dominio.RecomendacaoItem {
+	limite := len(itens)
+	if cfg.TopN > 0 && cfg.TopN < limite {
+		limite = cfg.TopN
+	}
+
+	if !cfg.Habilitado || len(itens) < 2 {
+		return itens[:limite]
+	}
+
+	maiorPontuacao := itens[0].Pontuacao
+	for _, item := range itens {
+		if item.Pontuacao > maiorPontuacao {
+			maiorPontuacao = item.Pontuacao
+		}
+	}
+
+	candidatos := make([]dominio.RecomendacaoItem, len(itens))
+	copy(candidatos, itens)
+	selecionados := make([]dominio.RecomendacaoItem, 0, limite)
+
+	for len(selecionados) < limite {
+		melhor, melhorValor := 0, 0.0
+		for i, candidato := range candidatos {
+			relevancia := candidato.Pontuacao / maiorPontuacao
+
+			maiorSimilaridade := 0.0
+			for _, escolhido := range selecionados {
+				if sim := similaridade(candidato.Produto, escolhido.Produto, cfg); sim > maiorSimilaridade {
+					maiorSimilaridade = sim
+				}
+			}
+
+			valor := cfg.Lambda*relevancia - (1-cfg.Lambda)*maiorSimilaridade
+			if i == 0 || valor > melhorValor {
+				melhor, melhorValor = i, valor
+			}
+		}
+
+		selecionados = append(selecionados, candidatos[melhor])
+		candidatos = append(candidatos[:melhor], candidatos[melhor+1:]...)
+	}
+
+	return selecionados
+}
+
+// similaridade vai de 0 (tipo e risco diferentes) a 1 (mesmo tipo e mesmo risco)
+func similaridade(a, b dominio.Produto, cfg ConfigReranqueamento) float64 {
+	total := cfg.PesoTipo + cfg.PesoRisco
+	if total == 0 {
+		return 0
+	}
+
+	sim := 0.0
+	if a.TipoProduto == b.TipoProduto {
+		sim += cfg.PesoTipo
+	}
+	if a.RiscoAssociado == b.RiscoAssociado {
+		sim += cfg.PesoRisco
+	}
+	return sim / total
+}
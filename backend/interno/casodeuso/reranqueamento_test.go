@@ -0,0 +1,107 @@
+package casodeuso
+
+import (
+	"slices"
+	"testing"
+
+	"backend/interno/dominio"
+)
+
+func itemTeste(id, tipo string, risco dominio.NivelRisco, pontuacao float64) dominio.RecomendacaoItem {
+	return dominio.RecomendacaoItem{
+		Produto:   dominio.Produto{ID: id, TipoProduto: tipo, RiscoAssociado: risco},
+		Pontuacao: pontuacao,
+	}
+}
+
+func idsItens(itens []dominio.RecomendacaoItem) []string {
+	ids := make([]string, len(itens))
+	for i, item := range itens {
+		ids[i] = item.Produto.ID
+	}
+	return ids
+}
+
+func TestReranquear(t *testing.T) {
+	// dois CDBs quase empatados no topo e um fundo de ações um pouco atrás
+	itens := []dominio.RecomendacaoItem{
+		itemTeste("cdb1", "CDB", dominio.RiscoBaixo, 1.0),
+		itemTeste("cdb2", "CDB", dominio.RiscoBaixo, 0.95),
+		itemTeste("acoes", "Fundo de Ações", dominio.RiscoAlto, 0.8),
+		itemTeste("lci", "LCI/LCA", dominio.RiscoBaixo, 0.5),
+	}
+	base := ConfigReranqueamento{Habilitado: true, Lambda: 0.7, PesoTipo: 0.7, PesoRisco: 0.3}
+
+	casos := []struct {
+		nome     string
+		ajustar  func(cfg *ConfigReranqueamento)
+		esperado []string
+	}{
+		{
+			nome:     "desabilitado mantém a ordem",
+			ajustar:  func(cfg *ConfigReranqueamento) { cfg.Habilitado = false },
+			esperado: []string{"cdb1", "cdb2", "acoes", "lci"},
+		},
+		{
+			nome:     "desabilitado ainda aplica o top-N",
+			ajustar:  func(cfg *ConfigReranqueamento) { cfg.Habilitado = false; cfg.TopN = 2 },
+			esperado: []string{"cdb1", "cdb2"},
+		},
+		{
+			nome:     "lambda 1 considera só a relevância",
+			ajustar:  func(cfg *ConfigReranqueamento) { cfg.Lambda = 1 },
+			esperado: []string{"cdb1", "cdb2", "acoes", "lci"},
+		},
+		{
+			nome:     "produto diferente sobe acima do quase idêntico",
+			ajustar:  func(cfg *ConfigReranqueamento) {},
+			esperado: []string{"cdb1", "acoes", "cdb2", "lci"},
+		},
+		{
+			nome:     "top-N corta depois da diversificação",
+			ajustar:  func(cfg *ConfigReranqueamento) { cfg.TopN = 2 },
+			esperado: []string{"cdb1", "acoes"},
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			cfg := base
+			c.ajustar(&cfg)
+			entrada := slices.Clone(itens)
+
+			if ids := idsItens(Reranquear(entrada, cfg)); !slices.Equal(ids, c.esperado) {
+				t.Errorf("ordem = %v, esperado %v", ids, c.esperado)
+			}
+			if !slices.Equal(idsItens(entrada), idsItens(itens)) {
+				t.Error("a lista de entrada foi alterada")
+			}
+		})
+	}
+}
+
+func TestSimilaridade(t *testing.T) {
+	cfg := ConfigReranqueamento{PesoTipo: 0.7, PesoRisco: 0.3}
+	cdb := dominio.Produto{TipoProduto: "CDB", RiscoAssociado: dominio.RiscoBaixo}
+
+	casos := []struct {
+		nome     string
+		outro    dominio.Produto
+		cfg      ConfigReranqueamento
+		esperado float64
+	}{
+		{"mesmo tipo e risco", cdb, cfg, 1},
+		{"só o tipo", dominio.Produto{TipoProduto: "CDB", RiscoAssociado: dominio.RiscoMedio}, cfg, 0.7},
+		{"só o risco", dominio.Produto{TipoProduto: "LCI/LCA", RiscoAssociado: dominio.RiscoBaixo}, cfg, 0.3},
+		{"nada em comum", dominio.Produto{TipoProduto: "Fundo de Ações", RiscoAssociado: dominio.RiscoAlto}, cfg, 0},
+		{"pesos zerados", cdb, ConfigReranqueamento{}, 0},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if sim := similaridade(cdb, c.outro, c.cfg); !quaseIgual(sim, c.esperado) {
+				t.Errorf("similaridade = %v, esperado %v", sim, c.esperado)
+			}
+		})
+	}
+}
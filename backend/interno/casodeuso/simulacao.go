@@ -0,0 +1,100 @@
+package casodeuso
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// ParametrosSimulacao descreve um cliente hipotético; nada é lido ou gravado sobre clientes reais
+type ParametrosSimulacao struct {
+	Cliente    dominio.Cliente
+	Posicoes   []dominio.Posicao // apenas ProdutoID e Saldo são usados; o restante vem do catálogo
+	Interacoes []dominio.Interacao
+	Estrategia string // vazio usa a estratégia padrão
+	Opcoes     OpcoesBusca
+}
+
+// Simular roda o mesmo pipeline de Executar contra o catálogo atual sem persistir o resultado
+func (s *ServicoRecomendacao) Simular(params ParametrosSimulacao) (*dominio.ResultadoRecomendacao, error) {
+	cliente := params.Cliente
+	if !cliente.PerfilRisco.Valido() {
+		return nil, fmt.Errorf("%w: perfil_risco obrigatório (Conservador, Moderado ou Arrojado)", dominio.ErrSimulacaoInvalida)
+	}
+	if cliente.Patrimonio < 0 {
+		return nil, fmt.Errorf("%w: patrimônio não pode ser negativo", dominio.ErrSimulacaoInvalida)
+	}
+
+	estrategia := params.Estrategia
+	if estrategia == "" {
+		estrategia = EstrategiaPadrao
+	}
+	registro, existe := s.estrategias.Obter(estrategia)
+	if !existe {
+		return nil, fmt.Errorf("%w: estratégia não registrada: %s", dominio.ErrSimulacaoInvalida, estrategia)
+	}
+
+	produtos, err := s.repo.ListarProdutosAtivos(context.Background())
+	if err != nil {
+		slog.Error("Falha ao listar produtos ativos", "erro", err)
+		return nil, err
+	}
+	catalogo := make(map[string]dominio.Produto, len(produtos))
+	for _, p := range produtos {
+		catalogo[p.ID] = p
+	}
+
+	// completa as posições informadas com os dados do catálogo, como faz ListarPosicoes
+	posicoes := make([]dominio.Posicao, 0, len(params.Posicoes))
+	for _, p := range params.Posicoes {
+		prod, ok := catalogo[p.ProdutoID]
+		if !ok {
+			return nil, fmt.Errorf("%w: produto da posição não encontrado no catálogo ativo: %s", dominio.ErrSimulacaoInvalida, p.ProdutoID)
+		}
+		if p.Saldo <= 0 {
+			continue
+		}
+		posicoes = append(posicoes, dominio.Posicao{
+			ProdutoID:      prod.ID,
+			NomeProduto:    prod.Nome,
+			TipoProduto:    prod.TipoProduto,
+			RiscoAssociado: prod.RiscoAssociado,
+			Saldo:          p.Saldo,
+		})
+	}
+
+	agora := time.Now()
+	interacoes := make([]dominio.Interacao, len(params.Interacoes))
+	copy(interacoes, params.Interacoes)
+	for i := range interacoes {
+		// interações sem data são tratadas como feitas agora
+		if interacoes[i].Data.IsZero() {
+			interacoes[i].Data = agora
+		}
+	}
+
+	cfg := s.ConfigAtual()
+	ctxPontuacao := novoContextoPontuacao(&cliente, cfg, agora, posicoes, interacoes)
+	recomendacoes, exclusoes := s.calcular(ctxPontuacao, registro.Ativas(), produtos, params.Opcoes.Filtro)
+
+	resultado := &dominio.ResultadoRecomendacao{
+		VersaoConfig:  cfg.Versao,
+		Estrategia:    estrategia,
+		Recomendacoes: recomendacoes,
+		Exclusoes:     exclusoes,
+	}
+	if params.Opcoes.IncluirAlocacao {
+		resultado.Alocacao = CalcularAlocacao(recomendacoes, &cliente, cfg.Alocacao)
+	}
+
+	slog.Info("Simulação de recomendação concluída",
+		"perfil_risco", cliente.PerfilRisco.String(),
+		"estrategia", estrategia,
+		"total_recomendacoes", len(recomendacoes),
+		"produtos_excluidos", len(exclusoes),
+	)
+	return resultado, nil
+}
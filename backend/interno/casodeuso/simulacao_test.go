@@ -0,0 +1,82 @@
+package casodeuso
+
+import (
+	"errors"
+	"testing"
+
+	"backend/interno/dominio"
+)
+
+// catalogoSimulacao tem quatro produtos idênticos, adequados ao perfil moderado
+func catalogoSimulacao() []dominio.Produto {
+	produtos := make([]dominio.Produto, 0, 4)
+	for _, id := range []string{"carteira", "interesse", "neutro", "outro"} {
+		produtos = append(produtos, dominio.Produto{ID: id, Nome: id, TipoProduto: "CDB",
+			RiscoAssociado: dominio.RiscoMedio, Rentabilidade12m: 12, Liquidez: "D+0"})
+	}
+	return produtos
+}
+
+func TestSimular(t *testing.T) {
+	repo := novoRepositorioFake()
+	repo.produtos = catalogoSimulacao()
+	servico := NovoServicoRecomendacao(repo, nil)
+
+	resultado, err := servico.Simular(ParametrosSimulacao{
+		Cliente:    dominio.Cliente{PerfilRisco: dominio.PerfilModerado, Patrimonio: 100000},
+		Posicoes:   []dominio.Posicao{{ProdutoID: "carteira", Saldo: 5000}, {ProdutoID: "outro", Saldo: 0}},
+		Interacoes: []dominio.Interacao{{ProdutoID: "interesse", Tipo: "Clique"}},
+		Opcoes:     OpcoesBusca{IncluirAlocacao: true},
+	})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	pontuacao := make(map[string]float64)
+	for _, item := range resultado.Recomendacoes {
+		pontuacao[item.Produto.ID] = item.Pontuacao
+	}
+	// a interação sem data conta como feita agora e a posição com saldo penaliza o produto
+	if pontuacao["interesse"] <= pontuacao["neutro"] {
+		t.Errorf("pontuações = %v, esperado interesse acima de neutro", pontuacao)
+	}
+	if p, existe := pontuacao["carteira"]; existe && p >= pontuacao["neutro"] {
+		t.Errorf("pontuações = %v, esperado carteira abaixo de neutro", pontuacao)
+	}
+	// posição zerada é ignorada
+	if !quaseIgual(pontuacao["outro"], pontuacao["neutro"]) {
+		t.Errorf("pontuações = %v, esperado outro igual a neutro", pontuacao)
+	}
+
+	if resultado.Estrategia != EstrategiaPadrao || resultado.ID != "" || resultado.Alocacao == nil {
+		t.Errorf("resultado = %+v, esperado estratégia padrão, sem id e com alocação", resultado)
+	}
+	if len(repo.recomendacoes) != 0 {
+		t.Errorf("simulação gravou %d recomendações", len(repo.recomendacoes))
+	}
+}
+
+func TestSimularInvalido(t *testing.T) {
+	valido := dominio.Cliente{PerfilRisco: dominio.PerfilModerado, Patrimonio: 100000}
+	casos := []struct {
+		nome   string
+		params ParametrosSimulacao
+	}{
+		{"perfil indefinido", ParametrosSimulacao{Cliente: dominio.Cliente{Patrimonio: 1000}}},
+		{"patrimônio negativo", ParametrosSimulacao{Cliente: dominio.Cliente{PerfilRisco: dominio.PerfilModerado, Patrimonio: -1}}},
+		{"estratégia não registrada", ParametrosSimulacao{Cliente: valido, Estrategia: "inexistente"}},
+		{"posição fora do catálogo", ParametrosSimulacao{Cliente: valido, Posicoes: []dominio.Posicao{{ProdutoID: "p9", Saldo: 10}}}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			repo := novoRepositorioFake()
+			repo.produtos = catalogoSimulacao()
+
+			_, err := NovoServicoRecomendacao(repo, nil).Simular(c.params)
+			if !errors.Is(err, dominio.ErrSimulacaoInvalida) {
+				t.Errorf("erro = %v, esperado simulação inválida", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package casodeuso
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+// carregarSupressoes indexa pelo produto as supressões ativas do cliente.
+// Com a supressão desativada na configuração nada é consultado.
+func (s *ServicoRecomendacao) carregarSupressoes(ctx context.Context, clienteID string, cfg ConfigPontuacao, agora time.Time) (map[string]dominio.Supressao, error) {
+	if cfg.Supressao.Dias == 0 {
+		return nil, nil
+	}
+
+	supressoes, err := s.repo.ListarSupressoesAtivas(ctx, clienteID, agora)
+	if err != nil {
+		return nil, err
+	}
+
+	porProduto := make(map[string]dominio.Supressao, len(supressoes))
+	for _, sup := range supressoes {
+		// com descartes repetidos vale a carência que termina por último
+		if atual, ok := porProduto[sup.ProdutoID]; !ok || sup.ExpiraEm.After(atual.ExpiraEm) {
+			porProduto[sup.ProdutoID] = sup
+		}
+	}
+	return porProduto, nil
+}
+
+// suprimirDescartados inicia a carência dos produtos descartados nos eventos de feedback
+func (s *ServicoRecomendacao) suprimirDescartados(recomendacao *dominio.ResultadoRecomendacao, eventos []dominio.EventoFeedback) error {
+	cfg := s.ConfigAtual()
+	if cfg.Supressao.Dias == 0 {
+		return nil
+	}
+
+	var supressoes []dominio.Supressao
+	for _, e := range eventos {
+		if e.Tipo != dominio.FeedbackDescartado {
+			continue
+		}
+		supressoes = append(supressoes, dominio.Supressao{
+			ClienteID:      recomendacao.ClienteID,
+			ProdutoID:      e.ProdutoID,
+			RecomendacaoID: recomendacao.ID,
+			Inicio:         e.Data,
+			ExpiraEm:       e.Data.AddDate(0, 0, cfg.Supressao.Dias),
+		})
+	}
+	if len(supressoes) == 0 {
+		return nil
+	}
+	return s.repo.SalvarSupressoes(supressoes)
+}
+
+// ListarSupressoes retorna os produtos do cliente que estão em carência
+func (s *ServicoRecomendacao) ListarSupressoes(clienteID string) ([]dominio.Supressao, error) {
+	ctx := context.Background()
+	if _, err := s.repo.ObterCliente(ctx, clienteID); err != nil {
+		return nil, err
+	}
+
+	supressoes, err := s.repo.ListarSupressoesAtivas(ctx, clienteID, time.Now())
+	if err != nil {
+		slog.Error("Falha ao listar supressões do cliente", "erro", err, "cliente_id", clienteID)
+		return nil, err
+	}
+	return supressoes, nil
+}
+
+// EncerrarSupressao permite que o assessor volte a recomendar um produto antes do fim da carência
+func (s *ServicoRecomendacao) EncerrarSupressao(clienteID, produtoID, responsavel string) error {
+	encerradas, err := s.repo.EncerrarSupressoes(clienteID, produtoID, responsavel, time.Now())
+	if err != nil {
+		slog.Error("Falha ao encerrar supressão", "erro", err, "cliente_id", clienteID, "id_produto", produtoID)
+		return err
+	}
+	if encerradas == 0 {
+		return dominio.ErrSupressaoNaoEncontrada
+	}
+
+	slog.Info("Supressão encerrada manualmente",
+		"cliente_id", clienteID,
+		"id_produto", produtoID,
+		"responsavel", responsavel,
+	)
+	return nil
+}
@@ -0,0 +1,106 @@
+package casodeuso
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/interno/dominio"
+)
+
+func TestFeedbackDescartadoSuprime(t *testing.T) {
+	casos := []struct {
+		nome       string
+		dias       int
+		tipo       dominio.TipoFeedback
+		suprimidos int
+	}{
+		{"descarte inicia a carência", 30, dominio.FeedbackDescartado, 1},
+		{"aceite não suprime", 30, dominio.FeedbackAceito, 0},
+		{"supressão desativada", 0, dominio.FeedbackDescartado, 0},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			repo := novoRepositorioFake(moderado)
+			repo.recomendacoes = []dominio.ResultadoRecomendacao{recomendacaoComExplicacao()}
+			servico := NovoServicoRecomendacao(repo, nil)
+			cfg := ConfigPadrao()
+			cfg.Supressao.Dias = c.dias
+			if err := servico.AtualizarConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := servico.RegistrarFeedback("r1", []dominio.EventoFeedback{{ProdutoID: "p1", Tipo: c.tipo}}); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if len(repo.supressoes) != c.suprimidos {
+				t.Fatalf("supressões = %+v, esperado %d", repo.supressoes, c.suprimidos)
+			}
+			if c.suprimidos == 0 {
+				return
+			}
+			sup := repo.supressoes[0]
+			if sup.ClienteID != "c1" || sup.ProdutoID != "p1" || sup.RecomendacaoID != "r1" {
+				t.Errorf("supressão = %+v, esperado p1 de c1 vinculado a r1", sup.Supressao)
+			}
+			if !sup.ExpiraEm.Equal(sup.Inicio.AddDate(0, 0, c.dias)) {
+				t.Errorf("carência de %v a %v, esperado %d dias", sup.Inicio, sup.ExpiraEm, c.dias)
+			}
+		})
+	}
+}
+
+func TestCarregarSupressoesMantemAUltima(t *testing.T) {
+	repo := novoRepositorioFake(moderado)
+	repo.SalvarSupressoes([]dominio.Supressao{
+		{ClienteID: "c1", ProdutoID: "p1", ExpiraEm: agoraTeste.AddDate(0, 0, 10)},
+		{ClienteID: "c1", ProdutoID: "p1", ExpiraEm: agoraTeste.AddDate(0, 0, 20)},
+		{ClienteID: "c1", ProdutoID: "p2", ExpiraEm: agoraTeste.AddDate(0, 0, -1)},
+	})
+	servico := NovoServicoRecomendacao(repo, nil)
+
+	supressoes, err := servico.carregarSupressoes(context.Background(), "c1", ConfigPadrao(), agoraTeste)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(supressoes) != 1 || !supressoes["p1"].ExpiraEm.Equal(agoraTeste.AddDate(0, 0, 20)) {
+		t.Errorf("supressões = %+v, esperado só p1 com a carência mais longa", supressoes)
+	}
+}
+
+func TestListarSupressoes(t *testing.T) {
+	repo := novoRepositorioFake(moderado)
+	repo.SalvarSupressoes([]dominio.Supressao{
+		{ClienteID: "c1", ProdutoID: "p1", ExpiraEm: time.Now().Add(time.Hour)},
+		{ClienteID: "c1", ProdutoID: "p2", ExpiraEm: time.Now().Add(-time.Hour)},
+	})
+	servico := NovoServicoRecomendacao(repo, nil)
+
+	supressoes, err := servico.ListarSupressoes("c1")
+	if err != nil || len(supressoes) != 1 || supressoes[0].ProdutoID != "p1" {
+		t.Errorf("supressões = %+v, erro = %v; esperado só p1", supressoes, err)
+	}
+	if _, err := servico.ListarSupressoes("c9"); !errors.Is(err, dominio.ErrClienteNaoEncontrado) {
+		t.Errorf("erro = %v, esperado cliente não encontrado", err)
+	}
+}
+
+func TestEncerrarSupressao(t *testing.T) {
+	repo := novoRepositorioFake(moderado)
+	repo.SalvarSupressoes([]dominio.Supressao{{ClienteID: "c1", ProdutoID: "p1", ExpiraEm: time.Now().Add(time.Hour)}})
+	servico := NovoServicoRecomendacao(repo, nil)
+
+	if err := servico.EncerrarSupressao("c1", "p1", "assessor@x"); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if ativas, _ := repo.ListarSupressoesAtivas(context.Background(), "c1", time.Now()); len(ativas) != 0 {
+		t.Errorf("supressões ativas = %+v, esperado nenhuma", ativas)
+	}
+	// a segunda tentativa não encontra supressão ativa
+	if err := servico.EncerrarSupressao("c1", "p1", "assessor@x"); !errors.Is(err, dominio.ErrSupressaoNaoEncontrada) {
+		t.Errorf("erro = %v, esperado supressão não encontrada", err)
+	}
+}
@@ -0,0 +1,111 @@
+package controladores
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"backend/interno/casodeuso"
+	"backend/interno/dominio"
+	"backend/interno/infraestrutura/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ControladorClientes struct {
+	servico *casodeuso.ServicoRecomendacao
+}
+
+func NovoControladorClientes(servico *casodeuso.ServicoRecomendacao) *ControladorClientes {
+	return &ControladorClientes{servico: servico}
+}
+
+// BuscarPosicao retorna a posição atual do cliente por produto
+// @Summary      Busca posição do cliente
+// @Description  Retorna o saldo líquido (aplicações menos resgates concluídos) do cliente em cada produto
+// @Tags         clientes
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "ID do Cliente"
+// @Success      200  {object}  dominio.PosicaoCliente
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/v2/clientes/{id}/posicao [get]
+func (h *ControladorClientes) BuscarPosicao(c *gin.Context) {
+	clienteID := c.Param("id")
+
+	slog.Info("Buscando posição do cliente", "cliente_id", clienteID)
+
+	posicao, err := h.servico.ObterPosicao(clienteID)
+	if errors.Is(err, dominio.ErrClienteNaoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
+		return
+	}
+	if err != nil {
+		slog.Error("Erro ao buscar posição do cliente", "erro", err, "cliente_id", clienteID)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, posicao)
+}
+
+// ListarSupressoes retorna os produtos descartados pelo cliente que ainda estão em carência
+// @Summary      Lista supressões do cliente
+// @Description  Retorna os produtos descartados pelo cliente que não voltam ao topo da recomendação até expirar a carência
+// @Tags         clientes
+// @Produce      json
+// @Param        id   path      string  true  "ID do Cliente"
+// @Success      200  {array}   dominio.Supressao
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/v2/clientes/{id}/supressoes [get]
+func (h *ControladorClientes) ListarSupressoes(c *gin.Context) {
+	clienteID := c.Param("id")
+
+	supressoes, err := h.servico.ListarSupressoes(clienteID)
+	if errors.Is(err, dominio.ErrClienteNaoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, supressoes)
+}
+
+// EncerrarSupressao permite ao assessor voltar a recomendar um produto descartado
+// @Summary      Encerra supressão de produto
+// @Description  Encerra a carência de um produto descartado pelo cliente antes do prazo. O usuário autenticado fica registrado como responsável.
+// @Tags         clientes
+// @Produce      json
+// @Param        id         path      string  true  "ID do Cliente"
+// @Param        produtoId  path      string  true  "ID do Produto"
+// @Success      204
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/v2/clientes/{id}/supressoes/{produtoId} [delete]
+func (h *ControladorClientes) EncerrarSupressao(c *gin.Context) {
+	clienteID := c.Param("id")
+	produtoID := c.Param("produtoId")
+
+	err := h.servico.EncerrarSupressao(clienteID, produtoID, middleware.GetUID(c))
+	if errors.Is(err, dominio.ErrSupressaoNaoEncontrada) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Nenhuma supressão ativa para o produto"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,113 @@
+package controladores
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"backend/interno/casodeuso"
+	"backend/interno/dominio"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ControladorFeedback struct {
+	servico *casodeuso.ServicoRecomendacao
+}
+
+func NovoControladorFeedback(servico *casodeuso.ServicoRecomendacao) *ControladorFeedback {
+	return &ControladorFeedback{servico: servico}
+}
+
+// FeedbackRequest representa os eventos enviados para uma recomendação
+type FeedbackRequest struct {
+	Eventos []EventoFeedbackRequest `json:"eventos" binding:"required,min=1,dive"`
+}
+
+// EventoFeedbackRequest é a reação do cliente a um item da recomendação
+type EventoFeedbackRequest struct {
+	ProdutoID string `json:"id_produto" binding:"required"`
+	Tipo      string `json:"tipo" binding:"required" example:"aceito" enums:"visualizado,clicado,aceito,descartado"`
+}
+
+// RegistrarFeedback grava a reação do cliente aos itens de uma recomendação
+// @Summary      Registra feedback de uma recomendação
+// @Description  Registra eventos por item (visualizado, clicado, aceito, descartado) vinculados ao id da recomendação
+// @Tags         feedback
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string           true  "ID da Recomendação"
+// @Param        request  body      FeedbackRequest  true  "Eventos de feedback"
+// @Success      201  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/v2/recomendacoes/{id}/feedback [post]
+func (h *ControladorFeedback) RegistrarFeedback(c *gin.Context) {
+	// o gin exige o mesmo nome de parâmetro na posição compartilhada com /recomendacoes/:clienteId,
+	// mas aqui o segmento é o id da recomendação
+	recomendacaoID := c.Param("clienteId")
+
+	var req FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos: " + err.Error()})
+		return
+	}
+
+	eventos := make([]dominio.EventoFeedback, len(req.Eventos))
+	for i, e := range req.Eventos {
+		eventos[i] = dominio.EventoFeedback{ProdutoID: e.ProdutoID, Tipo: dominio.TipoFeedback(e.Tipo)}
+	}
+
+	err := h.servico.RegistrarFeedback(recomendacaoID, eventos)
+	if errors.Is(err, dominio.ErrRecomendacaoNaoEncontrada) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Recomendação não encontrada"})
+		return
+	}
+	if errors.Is(err, dominio.ErrFeedbackInvalido) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+	if err != nil {
+		slog.Error("Erro ao registrar feedback", "erro", err, "id_recomendacao", recomendacaoID)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"mensagem": "Feedback registrado"})
+}
+
+// BuscarMetricas retorna as taxas de aceitação agregadas
+// @Summary      Métricas de feedback
+// @Description  Retorna, por produto e por regra, a contagem de itens visualizados, clicados, aceitos e descartados e a taxa de aceitação (aceitos / (aceitos + descartados))
+// @Tags         feedback
+// @Produce      json
+// @Param        desde  query     string  false  "Considera eventos a partir da data (AAAA-MM-DD)"
+// @Success      200  {object}  dominio.MetricasFeedback
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/v2/feedback/metricas [get]
+func (h *ControladorFeedback) BuscarMetricas(c *gin.Context) {
+	var desde time.Time
+	if valor := c.Query("desde"); valor != "" {
+		data, err := time.Parse("2006-01-02", valor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": "desde deve estar no formato AAAA-MM-DD"})
+			return
+		}
+		desde = data
+	}
+
+	metricas, err := h.servico.MetricasFeedback(desde)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, metricas)
+}
@@ -0,0 +1,49 @@
+package controladores
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"backend/interno/casodeuso"
+	"backend/interno/dominio"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ControladorJobs struct {
+	servico *casodeuso.ServicoRecomendacao
+}
+
+func NovoControladorJobs(servico *casodeuso.ServicoRecomendacao) *ControladorJobs {
+	return &ControladorJobs{servico: servico}
+}
+
+// BuscarJob retorna o andamento de uma solicitação de geração
+// @Summary      Busca status de geração
+// @Description  Retorna status (pendente, processando, concluido, falhou), datas, erro e o id da recomendação gerada
+// @Tags         jobs
+// @Produce      json
+// @Param        id   path      string  true  "ID do Job"
+// @Success      200  {object}  dominio.Job
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/v2/jobs/{id} [get]
+func (h *ControladorJobs) BuscarJob(c *gin.Context) {
+	jobID := c.Param("id")
+
+	job, err := h.servico.BuscarJob(jobID)
+	if errors.Is(err, dominio.ErrJobNaoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Job não encontrado"})
+		return
+	}
+	if err != nil {
+		slog.Error("Erro ao buscar job", "erro", err, "id_job", jobID)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
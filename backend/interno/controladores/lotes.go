@@ -0,0 +1,83 @@
+package controladores
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"backend/interno/casodeuso"
+	"backend/interno/dominio"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ControladorLotes struct {
+	servico *casodeuso.ServicoRecomendacao
+}
+
+func NovoControladorLotes(servico *casodeuso.ServicoRecomendacao) *ControladorLotes {
+	return &ControladorLotes{servico: servico}
+}
+
+// BuscarLote retorna o progresso de uma geração em massa
+// @Summary      Progresso da geração em massa
+// @Description  Retorna total de clientes, publicados, concluídos, falhos, progresso e estimativa de conclusão do lote
+// @Tags         lotes
+// @Produce      json
+// @Param        id   path      string  true  "ID do Lote"
+// @Success      200  {object}  dominio.Lote
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/v2/lotes/{id} [get]
+func (h *ControladorLotes) BuscarLote(c *gin.Context) {
+	loteID := c.Param("id")
+
+	lote, err := h.servico.BuscarLote(loteID)
+	if errors.Is(err, dominio.ErrLoteNaoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Lote não encontrado"})
+		return
+	}
+	if err != nil {
+		slog.Error("Erro ao buscar lote", "erro", err, "id_lote", loteID)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lote)
+}
+
+// CancelarLote interrompe uma geração em massa em andamento
+// @Summary      Cancela geração em massa
+// @Description  Interrompe a publicação e faz o worker descartar as mensagens ainda não processadas do lote
+// @Tags         lotes
+// @Produce      json
+// @Param        id   path      string  true  "ID do Lote"
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/v2/lotes/{id}/cancelar [post]
+func (h *ControladorLotes) CancelarLote(c *gin.Context) {
+	loteID := c.Param("id")
+
+	err := h.servico.CancelarLote(loteID)
+	if errors.Is(err, dominio.ErrLoteNaoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Lote não encontrado"})
+		return
+	}
+	if errors.Is(err, dominio.ErrLoteFinalizado) {
+		c.JSON(http.StatusConflict, gin.H{"erro": "Lote já finalizado"})
+		return
+	}
+	if err != nil {
+		slog.Error("Erro ao cancelar lote", "erro", err, "id_lote", loteID)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mensagem": "Lote cancelado", "id_lote": loteID})
+}
@@ -1,10 +1,19 @@
 package controladores
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"backend/interno/casodeuso"
+	"backend/interno/dominio"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,46 +26,168 @@ func NovoControladorRecomendacoes(servico *casodeuso.ServicoRecomendacao) *Contr
 	return &ControladorRecomendacoes{servico: servico}
 }
 
-// GerarRecomendacoes gera novas recomendações para um cliente de forma assíncrona
+// limites do modo síncrono; o máximo fica abaixo do WriteTimeout do servidor (15s)
+const (
+	timeoutSincronoPadrao = 5 * time.Second
+	timeoutSincronoMaximo = 12 * time.Second
+)
+
+// GerarRecomendacoes gera novas recomendações para um cliente (assíncrono por padrão)
 // @Summary      Solicita geração de recomendação
-// @Description  Publica uma mensagem para gerar recomendações em background para o cliente informado
+// @Description  Publica uma mensagem para gerar recomendações em background para o cliente informado.
+// @Description  Os filtros informados são enviados junto com a mensagem e aplicados na geração.
+// @Description  Com modo=sincrono a geração roda na própria requisição e retorna 200 com o resultado; se o timeout
+// @Description  for excedido, responde 202 e a geração é repassada ao worker assíncrono.
+// @Description  A resposta 202 traz o id_job, acompanhado em GET /api/v2/jobs/{id}.
 // @Tags         recomendacoes
 // @Accept       json
 // @Produce      json
-// @Param        clienteId   path      string  true  "ID do Cliente"
+// @Param        clienteId         path      string  true   "ID do Cliente"
+// @Param        limite            query     int     false  "Quantidade máxima de itens"
+// @Param        pontuacao_minima  query     number  false  "Descarta itens com pontuação menor"
+// @Param        tipo_produto      query     string  false  "Filtra por tipo de produto (aceita vários, separados por vírgula)"
+// @Param        risco             query     string  false  "Filtra por risco: Baixo, Médio ou Alto (aceita vários, separados por vírgula)"
+// @Param        modo              query     string  false  "assincrono (padrão) ou sincrono"  Enums(assincrono, sincrono)
+// @Param        timeout           query     string  false  "Prazo do modo síncrono (ex: 3s, 500ms; padrão 5s, máximo 12s)"
+// @Success      200  {object}  dominio.ResultadoRecomendacao
 // @Success      202  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
 // @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
 // @Security     BearerAuth
 // @Router       /api/v2/recomendacoes/{clienteId} [post]
 func (h *ControladorRecomendacoes) GerarRecomendacoes(c *gin.Context) {
 	clienteID := c.Param("clienteId")
 
+	opcoes, err := lerFiltroItens(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("modo", "assincrono") {
+	case "assincrono":
+	case "sincrono":
+		h.gerarSincrono(c, clienteID, opcoes)
+		return
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "modo deve ser assincrono ou sincrono"})
+		return
+	}
+
 	slog.Info("Solicitando geração de recomendações (async)", "cliente_id", clienteID)
 
-	h.servico.SolicitarGeracao(clienteID)
+	job, err := h.servico.SolicitarGeracao(clienteID, opcoes)
+	if errors.Is(err, dominio.ErrClienteNaoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
+		return
+	}
+	if errors.Is(err, dominio.ErrFilaIndisponivel) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"erro": "Fila de processamento indisponível, tente novamente"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro ao registrar solicitação"})
+		return
+	}
 
 	c.JSON(http.StatusAccepted, gin.H{
 		"mensagem":   "Solicitação recebida com sucesso",
 		"cliente_id": clienteID,
+		"id_job":     job.ID,
 	})
 }
 
-// GerarRecomendacoesMassiva dispara geração de recomendações para todos os clientes
+// gerarSincrono executa a geração dentro da requisição, respeitando o timeout informado
+func (h *ControladorRecomendacoes) gerarSincrono(c *gin.Context, clienteID string, opcoes dominio.FiltroItens) {
+	timeout := timeoutSincronoPadrao
+	if valor := c.Query("timeout"); valor != "" {
+		d, err := time.ParseDuration(valor)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"erro": fmt.Sprintf("timeout inválido: %q", valor)})
+			return
+		}
+		timeout = min(d, timeoutSincronoMaximo)
+	}
+
+	slog.Info("Gerando recomendações (sync)", "cliente_id", clienteID, "timeout", timeout.String())
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	resultado, job, err := h.servico.ExecutarComPrazo(ctx, clienteID, opcoes)
+	if errors.Is(err, dominio.ErrPrazoExcedido) {
+		c.JSON(http.StatusAccepted, gin.H{
+			"mensagem":   "Prazo excedido; a geração foi repassada ao processamento em background",
+			"cliente_id": clienteID,
+			"id_job":     job.ID,
+		})
+		return
+	}
+	if errors.Is(err, dominio.ErrClienteNaoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
+		return
+	}
+	if errors.Is(err, dominio.ErrFilaIndisponivel) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"erro": "Fila de processamento indisponível, tente novamente"})
+		return
+	}
+	if err != nil {
+		slog.Error("Erro na geração síncrona de recomendações", "erro", err, "cliente_id", clienteID)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resultado)
+}
+
+// GeracaoMassaRequest restringe a geração em massa a um segmento; sem corpo, todos os clientes são incluídos
+type GeracaoMassaRequest struct {
+	Segmento dominio.SegmentoClientes `json:"segmento"`
+}
+
+// GerarRecomendacoesMassiva dispara geração de recomendações para todos os clientes ou para um segmento
 // @Summary      Gera recomendações em massa
-// @Description  Dispara processo assíncrono para gerar recomendações para todos os clientes
+// @Description  Dispara processo assíncrono para gerar recomendações para todos os clientes, ou apenas para os do segmento informado no corpo.
+// @Description  Os critérios do segmento são combinados entre si; critérios omitidos não filtram.
+// @Description  A resposta traz o id_lote, acompanhado em GET /api/v2/lotes/{id}.
 // @Tags         recomendacoes
 // @Accept       json
 // @Produce      json
+// @Param        request           body      GeracaoMassaRequest  false  "Segmento de clientes (opcional)"
+// @Param        limite            query     int     false  "Quantidade máxima de itens"
+// @Param        pontuacao_minima  query     number  false  "Descarta itens com pontuação menor"
+// @Param        tipo_produto      query     string  false  "Filtra por tipo de produto (aceita vários, separados por vírgula)"
+// @Param        risco             query     string  false  "Filtra por risco: Baixo, Médio ou Alto (aceita vários, separados por vírgula)"
 // @Success      202  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
 // @Failure      401  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Security     BearerAuth
 // @Router       /api/v2/recomendacoes [post]
 func (h *ControladorRecomendacoes) GerarRecomendacoesMassiva(c *gin.Context) {
+	var req GeracaoMassaRequest
+	// o corpo é opcional para manter compatível a chamada sem segmento
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos: " + err.Error()})
+		return
+	}
+
+	opcoes, err := lerFiltroItens(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
 	slog.Info("Iniciando processo de geração de recomendações em massa")
 
-	err := h.servico.GerarEmMassa()
+	lote, err := h.servico.GerarEmMassa(opcoes, req.Segmento)
+	if errors.Is(err, dominio.ErrSegmentoInvalido) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
 	if err != nil {
 		slog.Error("Erro ao iniciar geração em massa", "erro", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -67,6 +198,7 @@ func (h *ControladorRecomendacoes) GerarRecomendacoesMassiva(c *gin.Context) {
 
 	c.JSON(http.StatusAccepted, gin.H{
 		"mensagem": "Processo de geração de recomendações iniciado com sucesso. As recomendações serão geradas em background.",
+		"id_lote":  lote.ID,
 	})
 }
 
@@ -76,10 +208,17 @@ func (h *ControladorRecomendacoes) GerarRecomendacoesMassiva(c *gin.Context) {
 // @Tags         recomendacoes
 // @Accept       json
 // @Produce      json
-// @Param        clienteId   path      string  true  "ID do Cliente"
+// @Param        clienteId         path      string  true   "ID do Cliente"
+// @Param        limite            query     int     false  "Quantidade máxima de itens"
+// @Param        pontuacao_minima  query     number  false  "Descarta itens com pontuação menor"
+// @Param        tipo_produto      query     string  false  "Filtra por tipo de produto (aceita vários, separados por vírgula)"
+// @Param        risco             query     string  false  "Filtra por risco: Baixo, Médio ou Alto (aceita vários, separados por vírgula)"
+// @Param        alocacao          query     bool    false  "Inclui a proposta de alocação por produto"
 // @Success      200  {object}  dominio.ResultadoRecomendacao
+// @Failure      400  {object}  map[string]string
 // @Failure      401  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
 // @Security     BearerAuth
 // @Router       /api/v2/recomendacoes/{clienteId} [get]
 func (h *ControladorRecomendacoes) BuscarRecomendacoes(c *gin.Context) {
@@ -87,7 +226,18 @@ func (h *ControladorRecomendacoes) BuscarRecomendacoes(c *gin.Context) {
 
 	slog.Info("Buscando recomendações", "cliente_id", clienteID)
 
-	resultado, err := h.servico.BuscarUltima(clienteID)
+	filtro, err := lerFiltroItens(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+	opcoes := casodeuso.OpcoesBusca{Filtro: filtro, IncluirAlocacao: c.Query("alocacao") == "true"}
+
+	resultado, err := h.servico.BuscarUltima(clienteID, opcoes)
+	if errors.Is(err, dominio.ErrClienteNaoEncontrado) {
+		c.JSON(http.StatusNotFound, gin.H{"erro": "Cliente não encontrado"})
+		return
+	}
 	if err != nil {
 		slog.Error("Erro ao buscar recomendações", "erro", err, "cliente_id", clienteID)
 		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
@@ -104,6 +254,53 @@ func (h *ControladorRecomendacoes) BuscarRecomendacoes(c *gin.Context) {
 	c.JSON(http.StatusOK, resultado)
 }
 
+// lerFiltroItens monta o filtro de itens a partir dos query params da requisição
+func lerFiltroItens(c *gin.Context) (dominio.FiltroItens, error) {
+	var filtro dominio.FiltroItens
+
+	if valor := c.Query("limite"); valor != "" {
+		limite, err := strconv.Atoi(valor)
+		if err != nil || limite < 0 {
+			return filtro, fmt.Errorf("limite inválido: %q", valor)
+		}
+		filtro.Limite = limite
+	}
+
+	if valor := c.Query("pontuacao_minima"); valor != "" {
+		minima, err := strconv.ParseFloat(valor, 64)
+		// NaN desligaria o filtro e, como Inf, não pode ser serializado nas opções do job ou do lote
+		if err != nil || math.IsNaN(minima) || math.IsInf(minima, 0) {
+			return filtro, fmt.Errorf("pontuacao_minima inválida: %q", valor)
+		}
+		filtro.PontuacaoMinima = minima
+	}
+
+	filtro.TiposProduto = lerLista(c, "tipo_produto")
+
+	for _, valor := range lerLista(c, "risco") {
+		nivel, err := dominio.ParseNivelRisco(valor)
+		if err != nil {
+			return filtro, err
+		}
+		filtro.Riscos = append(filtro.Riscos, nivel)
+	}
+
+	return filtro, nil
+}
+
+// lerLista aceita o parâmetro repetido (?a=1&a=2) ou separado por vírgula (?a=1,2)
+func lerLista(c *gin.Context, nome string) []string {
+	var valores []string
+	for _, valor := range c.QueryArray(nome) {
+		for _, parte := range strings.Split(valor, ",") {
+			if parte = strings.TrimSpace(parte); parte != "" {
+				valores = append(valores, parte)
+			}
+		}
+	}
+	return valores
+}
+
 // HealthCheck verifica se o serviço está funcionando
 // @Summary      Verificação de saúde
 // @Description  Retorna status OK se a API estiver no ar
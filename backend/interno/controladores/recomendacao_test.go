@@ -0,0 +1,39 @@
+package controladores
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLerFiltroItens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	casos := []struct {
+		nome   string
+		query  string
+		valido bool
+	}{
+		{"sem parâmetros", "", true},
+		{"pontuação mínima numérica", "pontuacao_minima=0.5", true},
+		{"pontuação mínima negativa", "pontuacao_minima=-1", true},
+		{"pontuação mínima NaN", "pontuacao_minima=NaN", false},
+		{"pontuação mínima infinita", "pontuacao_minima=Inf", false},
+		{"pontuação mínima infinita negativa", "pontuacao_minima=-Inf", false},
+		{"pontuação mínima não numérica", "pontuacao_minima=alta", false},
+		{"limite negativo", "limite=-1", false},
+		{"risco inválido", "risco=Altíssimo", false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest("GET", "/?"+c.query, nil)
+
+			if _, err := lerFiltroItens(ctx); (err == nil) != c.valido {
+				t.Errorf("válido = %v, esperado %v (erro: %v)", err == nil, c.valido, err)
+			}
+		})
+	}
+}
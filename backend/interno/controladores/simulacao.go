@@ -0,0 +1,95 @@
+package controladores
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"backend/interno/casodeuso"
+	"backend/interno/dominio"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ControladorSimulacoes struct {
+	servico *casodeuso.ServicoRecomendacao
+}
+
+func NovoControladorSimulacoes(servico *casodeuso.ServicoRecomendacao) *ControladorSimulacoes {
+	return &ControladorSimulacoes{servico: servico}
+}
+
+// SimulacaoRequest descreve o cliente hipotético a ser simulado
+type SimulacaoRequest struct {
+	PerfilRisco          dominio.PerfilRisco `json:"perfil_risco" swaggertype:"string" example:"Arrojado"`
+	Patrimonio           float64             `json:"patrimonio_total_estimado" example:"500000"`
+	ObjetivoInvestimento string              `json:"objetivo_investimento" example:"Crescimento de Capital"`
+	Estrategia           string              `json:"estrategia,omitempty" example:"padrao"` // vazio usa a estratégia padrão
+	Posicoes             []PosicaoSimulada   `json:"posicoes" binding:"dive"`
+	Interacoes           []dominio.Interacao `json:"interacoes"`
+}
+
+// PosicaoSimulada é o saldo hipotético do cliente em um produto do catálogo
+type PosicaoSimulada struct {
+	ProdutoID string  `json:"id_produto" binding:"required"`
+	Saldo     float64 `json:"saldo" example:"10000"`
+}
+
+// Simular calcula recomendações para um cliente hipotético sem gravar nada
+// @Summary      Simula recomendações
+// @Description  Roda o mesmo scoring da geração contra o catálogo atual para um perfil, posições e interações hipotéticos. O resultado não é persistido.
+// @Tags         simulacoes
+// @Accept       json
+// @Produce      json
+// @Param        request           body      SimulacaoRequest  true   "Cliente hipotético"
+// @Param        limite            query     int               false  "Quantidade máxima de itens"
+// @Param        pontuacao_minima  query     number            false  "Descarta itens com pontuação menor"
+// @Param        tipo_produto      query     string            false  "Filtra por tipo de produto (aceita vários, separados por vírgula)"
+// @Param        risco             query     string            false  "Filtra por risco: Baixo, Médio ou Alto (aceita vários, separados por vírgula)"
+// @Param        alocacao          query     bool              false  "Inclui a proposta de alocação por produto"
+// @Success      200  {object}  dominio.ResultadoRecomendacao
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /api/v2/simulacoes [post]
+func (h *ControladorSimulacoes) Simular(c *gin.Context) {
+	var req SimulacaoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": "Dados inválidos: " + err.Error()})
+		return
+	}
+
+	filtro, err := lerFiltroItens(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+
+	params := casodeuso.ParametrosSimulacao{
+		Cliente: dominio.Cliente{
+			PerfilRisco:          req.PerfilRisco,
+			Patrimonio:           req.Patrimonio,
+			ObjetivoInvestimento: req.ObjetivoInvestimento,
+		},
+		Interacoes: req.Interacoes,
+		Estrategia: req.Estrategia,
+		Opcoes:     casodeuso.OpcoesBusca{Filtro: filtro, IncluirAlocacao: c.Query("alocacao") == "true"},
+	}
+	for _, p := range req.Posicoes {
+		params.Posicoes = append(params.Posicoes, dominio.Posicao{ProdutoID: p.ProdutoID, Saldo: p.Saldo})
+	}
+
+	resultado, err := h.servico.Simular(params)
+	if errors.Is(err, dominio.ErrSimulacaoInvalida) {
+		c.JSON(http.StatusBadRequest, gin.H{"erro": err.Error()})
+		return
+	}
+	if err != nil {
+		slog.Error("Erro ao simular recomendações", "erro", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"erro": "Erro interno do servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resultado)
+}
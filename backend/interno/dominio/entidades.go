@@ -1,44 +1,200 @@
 package dominio
 
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // entidades de dominio que espelham o banco
 type Cliente struct {
-	ID          string  `json:"id_cliente"`
-	PerfilRisco string  `json:"perfil_risco"`
-	Patrimonio  float64 `json:"patrimonio_total_estimado"`
+	ID                   string      `json:"id_cliente"`
+	PerfilRisco          PerfilRisco `json:"perfil_risco" swaggertype:"string" example:"Moderado"`
+	Patrimonio           float64     `json:"patrimonio_total_estimado"`
+	ObjetivoInvestimento string      `json:"objetivo_investimento"`
 }
 
 type Produto struct {
-	ID               string  `json:"id_produto"`
-	Nome             string  `json:"nome_produto"`
-	RiscoAssociado   string  `json:"risco_associado"`
-	Rentabilidade12m float64 `json:"rentabilidade_12m"`
-	AplicacaoMinima  float64 `json:"aplicacao_minima"`
+	ID                string     `json:"id_produto"`
+	Nome              string     `json:"nome_produto"`
+	TipoProduto       string     `json:"tipo_produto"`
+	RiscoAssociado    NivelRisco `json:"risco_associado" swaggertype:"string" example:"Médio"`
+	Rentabilidade12m  float64    `json:"rentabilidade_12m"`
+	Rentabilidade36m  float64    `json:"rentabilidade_36m"` // acumulada no período, em %
+	TaxaAdministracao float64    `json:"taxa_administracao"`
+	AplicacaoMinima   float64    `json:"aplicacao_minima"`
+	Liquidez          string     `json:"liquidez"` // ex: D+0, D+30
+}
+
+// DiasLiquidez converte a liquidez no formato "D+N" para o número de dias até o resgate
+func (p Produto) DiasLiquidez() (int, bool) {
+	prazo, ok := strings.CutPrefix(strings.ToUpper(strings.TrimSpace(p.Liquidez)), "D+")
+	if !ok {
+		return 0, false
+	}
+	dias, err := strconv.Atoi(prazo)
+	if err != nil || dias < 0 {
+		return 0, false
+	}
+	return dias, true
+}
+
+// Interacao é um evento do cliente com um produto (visualização, clique, simulação...)
+type Interacao struct {
+	ProdutoID       string    `json:"id_produto"`
+	Tipo            string    `json:"tipo_interacao"`
+	Data            time.Time `json:"data_interacao"`
+	DuracaoSegundos int       `json:"duracao_interacao_segundos"`
+}
+
+// status de transação que efetivamente movimenta a posição do cliente
+const StatusTransacaoConcluida = "Concluida"
+
+// tipos de transação que alteram o saldo do cliente no produto
+const (
+	TipoTransacaoAplicacao = "Aplicacao"
+	TipoTransacaoResgate   = "Resgate"
+)
+
+// Transacao é uma movimentação concluída do cliente, usada na avaliação offline
+type Transacao struct {
+	ClienteID string
+	ProdutoID string
+	Tipo      string
+	Valor     float64
+	Data      time.Time
+}
+
+// Posicao é o saldo líquido do cliente em um produto (aplicações menos resgates concluídos)
+type Posicao struct {
+	ProdutoID      string     `json:"id_produto"`
+	NomeProduto    string     `json:"nome_produto"`
+	TipoProduto    string     `json:"tipo_produto"`
+	RiscoAssociado NivelRisco `json:"risco_associado" swaggertype:"string" example:"Médio"`
+	Saldo          float64    `json:"saldo"`
+}
+
+// PosicaoCliente é a carteira atual do cliente
+type PosicaoCliente struct {
+	ClienteID  string    `json:"id_cliente"`
+	SaldoTotal float64   `json:"saldo_total"`
+	Posicoes   []Posicao `json:"posicoes"`
 }
 
 // estruturas de retorno da api
 type RecomendacaoItem struct {
-	Produto   Produto `json:"produto"`
-	Pontuacao float64 `json:"pontuacao"`
-	Motivo    string  `json:"motivo"`
+	Produto    Produto             `json:"produto"`
+	Pontuacao  float64             `json:"pontuacao"`
+	Motivo     string              `json:"motivo"`     // resumo legível, mantido por compatibilidade
+	Explicacao []ContribuicaoRegra `json:"explicacao"` // detalhamento auditável da pontuação
+}
+
+// ContribuicaoRegra registra quanto uma regra somou à pontuação do item e com quais dados
+type ContribuicaoRegra struct {
+	RegraID   string                 `json:"regra_id"`
+	Pontos    float64                `json:"pontos"`
+	Descricao string                 `json:"descricao,omitempty"`
+	Entradas  map[string]interface{} `json:"entradas,omitempty"`
+}
+
+// ExclusaoProduto registra por que um produto foi barrado antes do scoring (trilha de compliance)
+type ExclusaoProduto struct {
+	ProdutoID   string                 `json:"id_produto"`
+	NomeProduto string                 `json:"nome_produto"`
+	FiltroID    string                 `json:"filtro_id"`
+	Motivo      string                 `json:"motivo"`
+	Entradas    map[string]interface{} `json:"entradas,omitempty"`
 }
 
 type ResultadoRecomendacao struct {
 	ID            string             `json:"id_recomendacao"` // uuid gerado
 	ClienteID     string             `json:"id_cliente"`
+	VersaoConfig  string             `json:"versao_config"`        // versão dos pesos/limiares que gerou o resultado
+	Estrategia    string             `json:"estrategia,omitempty"` // estratégia de scoring usada (teste A/B)
+	Variante      string             `json:"variante,omitempty"`   // grupo do experimento em que o cliente caiu
 	Recomendacoes []RecomendacaoItem `json:"recomendacoes"`
+	Exclusoes     []ExclusaoProduto  `json:"exclusoes"` // produtos barrados pelos filtros de elegibilidade
+	Alocacao      *Alocacao          `json:"alocacao,omitempty"`
+}
+
+// Alocacao é a proposta de quanto aplicar em cada produto recomendado
+type Alocacao struct {
+	MontanteBase    float64        `json:"montante_base"` // parcela do patrimônio considerada na proposta
+	ValorAlocado    float64        `json:"valor_alocado"`
+	ValorNaoAlocado float64        `json:"valor_nao_alocado"` // sobra que não coube nos mínimos e tetos
+	Itens           []ItemAlocacao `json:"itens"`
+}
+
+type ItemAlocacao struct {
+	ProdutoID      string     `json:"id_produto"`
+	NomeProduto    string     `json:"nome_produto"`
+	RiscoAssociado NivelRisco `json:"risco_associado" swaggertype:"string" example:"Médio"`
+	Valor          float64    `json:"valor"`
+	Percentual     float64    `json:"percentual"` // em relação ao montante base
 }
 
 // interface do repositorio (inversão de dependência)
 type RepositorioDados interface {
-	ObterCliente(id string) (*Cliente, error)
-	ListarProdutosAtivos() ([]Produto, error)
-	VerificarPosseProduto(clienteID, produtoID string) (bool, error)
-	VerificarInteracaoRecente(clienteID, produtoID string) (bool, error)
-	SalvarRecomendacao(clienteID string, itens []RecomendacaoItem) (string, error)
+	// os métodos usados pela geração recebem o contexto para que a consulta pare junto com o prazo
+	ObterCliente(ctx context.Context, id string) (*Cliente, error)
+	ListarProdutosAtivos(ctx context.Context) ([]Produto, error)
+	ListarPosicoes(ctx context.Context, clienteID string) ([]Posicao, error)
+	ListarInteracoes(ctx context.Context, clienteID string, desde time.Time) ([]Interacao, error)
+	// ListarHistoricoProdutos retorna os produtos que o cliente já aplicou ou com que já interagiu, em qualquer data
+	ListarHistoricoProdutos(ctx context.Context, clienteID string) (aplicados, interagidos map[string]bool, err error)
+	SalvarRecomendacao(ctx context.Context, resultado *ResultadoRecomendacao) (string, error)
 	BuscarUltimaRecomendacao(clienteID string) (*ResultadoRecomendacao, error)
-	ListarTodosClientes() ([]Cliente, error)
+	// ListarClientesApos pagina os clientes do segmento por id (keyset); cursor vazio começa do primeiro
+	ListarClientesApos(cursor string, limite int, segmento SegmentoClientes) ([]Cliente, error)
+	ContarClientes(segmento SegmentoClientes) (int, error)
+
+	BuscarRecomendacao(id string) (*ResultadoRecomendacao, error)
+	SalvarFeedback(eventos []EventoFeedback) error
+	// AgregarFeedback soma os eventos registrados a partir da data informada
+	AgregarFeedback(desde time.Time) (*MetricasFeedback, error)
+
+	SalvarSupressoes(supressoes []Supressao) error
+	// ListarSupressoesAtivas retorna as supressões não encerradas que ainda valem na data informada
+	ListarSupressoesAtivas(ctx context.Context, clienteID string, em time.Time) ([]Supressao, error)
+	// EncerrarSupressoes encerra as supressões ativas do produto e retorna quantas foram encerradas
+	EncerrarSupressoes(clienteID, produtoID, responsavel string, em time.Time) (int64, error)
+
+	CriarJob(ctx context.Context, clienteID string) (*Job, error)
+	BuscarJob(id string) (*Job, error)
+	// ReivindicarJob passa o job de pendente para processando e retorna false se ele já não estava
+	// pendente; só quem reivindica o job pode gravar a recomendação dele
+	ReivindicarJob(ctx context.Context, id string) (bool, error)
+	// AtualizarJob muda o status e registra o início (processando) ou o fim (concluido/falhou)
+	AtualizarJob(id string, status StatusJob, recomendacaoID, erro string) error
+
+	// CriarLote já reserva a publicação do lote para a instância que o criou pelo prazo informado
+	CriarLote(total int, opcoes FiltroItens, segmento SegmentoClientes, reserva time.Duration) (*Lote, error)
+	BuscarLote(id string) (*Lote, error)
+	// AtualizarPublicacaoLote grava o checkpoint da publicação e renova a reserva
+	AtualizarPublicacaoLote(id string, publicados int, cursor string, reserva time.Duration) error
+	// LiberarPublicacaoLote expira a reserva para que o lote seja retomado sem esperar o prazo
+	LiberarPublicacaoLote(id string) error
+	// FinalizarPublicacaoLote fixa o total no número de mensagens publicadas
+	FinalizarPublicacaoLote(id string, publicados int) error
+	// ReivindicarLotesPendentes reserva os lotes em andamento cuja publicação parou (reserva expirada)
+	ReivindicarLotesPendentes(reserva time.Duration) ([]Lote, error)
+	// RegistrarResultadoLote conta o cliente processado uma única vez, mesmo com mensagens
+	// repetidas, e conclui o lote no último; um sucesso posterior substitui a falha do cliente
+	RegistrarResultadoLote(id, clienteID string, sucesso bool) error
+	// CancelarLote retorna false se o lote já não estava em andamento
+	CancelarLote(id string) (bool, error)
+}
+
+// RepositorioHistorico fornece o histórico completo para reprocessar recomendações no passado
+type RepositorioHistorico interface {
+	// ListarTransacoesConcluidas retorna as transações concluídas em ordem cronológica
+	ListarTransacoesConcluidas() ([]Transacao, error)
+	// ListarInteracoesPorCliente retorna todas as interações, agrupadas pelo ID do cliente
+	ListarInteracoesPorCliente() (map[string][]Interacao, error)
 }
 
 type Publicador interface {
-	Publicar(topico string, payload interface{})
+	// Publicar retorna depois que a mensagem foi aceita pela fila, ou com o erro que a impediu
+	Publicar(topico string, payload interface{}) error
 }
@@ -0,0 +1,18 @@
+package dominio
+
+import "errors"
+
+// erros de domínio que os controladores traduzem para status HTTP
+var (
+	ErrClienteNaoEncontrado      = errors.New("cliente não encontrado")
+	ErrRecomendacaoNaoEncontrada = errors.New("recomendação não encontrada")
+	ErrFeedbackInvalido          = errors.New("feedback inválido")
+	ErrSupressaoNaoEncontrada    = errors.New("supressão ativa não encontrada")
+	ErrSimulacaoInvalida         = errors.New("simulação inválida")
+	ErrPrazoExcedido             = errors.New("prazo para geração síncrona excedido")
+	ErrJobNaoEncontrado          = errors.New("job não encontrado")
+	ErrLoteNaoEncontrado         = errors.New("lote não encontrado")
+	ErrLoteFinalizado            = errors.New("lote já finalizado")
+	ErrSegmentoInvalido          = errors.New("segmento inválido")
+	ErrFilaIndisponivel          = errors.New("fila de processamento indisponível")
+)
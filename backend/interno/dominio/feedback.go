@@ -0,0 +1,59 @@
+package dominio
+
+import (
+	"fmt"
+	"time"
+)
+
+// TipoFeedback é a reação do cliente a um item recomendado
+type TipoFeedback string
+
+const (
+	FeedbackVisualizado TipoFeedback = "visualizado"
+	FeedbackClicado     TipoFeedback = "clicado"
+	FeedbackAceito      TipoFeedback = "aceito"
+	FeedbackDescartado  TipoFeedback = "descartado"
+)
+
+// ParseTipoFeedback valida o tipo de evento recebido pela API
+func ParseTipoFeedback(s string) (TipoFeedback, error) {
+	switch tipo := TipoFeedback(normalizar(s)); tipo {
+	case FeedbackVisualizado, FeedbackClicado, FeedbackAceito, FeedbackDescartado:
+		return tipo, nil
+	}
+	return "", fmt.Errorf("tipo de feedback inválido: %q", s)
+}
+
+// EventoFeedback registra a reação do cliente a um item de uma recomendação persistida
+type EventoFeedback struct {
+	RecomendacaoID string       `json:"id_recomendacao"`
+	ProdutoID      string       `json:"id_produto"`
+	Tipo           TipoFeedback `json:"tipo"`
+	Data           time.Time    `json:"data"`
+	// Regras são as regras que contribuíram para a pontuação do item, usadas nas métricas por regra
+	Regras []string `json:"regras,omitempty"`
+}
+
+// MetricaFeedback agrega os eventos de um produto ou de uma regra.
+// Cada item de cada recomendação é contado no máximo uma vez por tipo de evento.
+type MetricaFeedback struct {
+	Chave         string  `json:"chave"` // id do produto ou da regra
+	Visualizados  int     `json:"visualizados"`
+	Clicados      int     `json:"clicados"`
+	Aceitos       int     `json:"aceitos"`
+	Descartados   int     `json:"descartados"`
+	TaxaAceitacao float64 `json:"taxa_aceitacao"` // aceitos / (aceitos + descartados)
+}
+
+// MetricasFeedback é o retorno agregado de feedback por produto e por regra
+type MetricasFeedback struct {
+	PorProduto []MetricaFeedback `json:"por_produto"`
+	PorRegra   []MetricaFeedback `json:"por_regra"`
+}
+
+// CalcularTaxa preenche a taxa de aceitação a partir dos contadores
+func (m *MetricaFeedback) CalcularTaxa() {
+	if decididos := m.Aceitos + m.Descartados; decididos > 0 {
+		m.TaxaAceitacao = float64(m.Aceitos) / float64(decididos)
+	}
+}
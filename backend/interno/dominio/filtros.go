@@ -0,0 +1,63 @@
+package dominio
+
+import "slices"
+
+// FiltroItens restringe a lista de recomendações, tanto na consulta quanto na geração
+type FiltroItens struct {
+	Limite          int          `json:"limite,omitempty"`           // 0 = sem limite
+	PontuacaoMinima float64      `json:"pontuacao_minima,omitempty"` // itens abaixo são descartados
+	TiposProduto    []string     `json:"tipos_produto,omitempty"`    // vazio = todos
+	Riscos          []NivelRisco `json:"riscos,omitempty" swaggertype:"array,string"`
+}
+
+// Filtrar mantém apenas os itens que atendem aos critérios, preservando a ordem
+func (f FiltroItens) Filtrar(itens []RecomendacaoItem) []RecomendacaoItem {
+	if f.PontuacaoMinima == 0 && len(f.TiposProduto) == 0 && len(f.Riscos) == 0 {
+		return itens
+	}
+
+	filtrados := make([]RecomendacaoItem, 0, len(itens))
+	for _, item := range itens {
+		if item.Pontuacao < f.PontuacaoMinima {
+			continue
+		}
+		if len(f.TiposProduto) > 0 && !slices.Contains(f.TiposProduto, item.Produto.TipoProduto) {
+			continue
+		}
+		if len(f.Riscos) > 0 && !slices.Contains(f.Riscos, item.Produto.RiscoAssociado) {
+			continue
+		}
+		filtrados = append(filtrados, item)
+	}
+	return filtrados
+}
+
+// Limitar corta a lista nos primeiros Limite itens
+func (f FiltroItens) Limitar(itens []RecomendacaoItem) []RecomendacaoItem {
+	if f.Limite > 0 && f.Limite < len(itens) {
+		return itens[:f.Limite]
+	}
+	return itens
+}
+
+// Aplicar filtra e depois limita a lista
+func (f FiltroItens) Aplicar(itens []RecomendacaoItem) []RecomendacaoItem {
+	return f.Limitar(f.Filtrar(itens))
+}
+
+// tópicos de geração de recomendações, compartilhados por quem publica (casodeuso) e
+// quem consome (worker)
+const (
+	// TopicoGerarRecomendacao recebe as gerações individuais, disparadas pelo app
+	TopicoGerarRecomendacao = "gerar-recomendacao"
+	// TopicoGerarRecomendacaoMassa recebe as mensagens dos lotes de geração em massa, de baixa prioridade
+	TopicoGerarRecomendacaoMassa = "gerar-recomendacao-massa"
+)
+
+// SolicitacaoGeracao é o payload publicado no tópico de geração de recomendações
+type SolicitacaoGeracao struct {
+	ClienteID string      `json:"id_cliente"`
+	JobID     string      `json:"id_job,omitempty"`  // vazio em mensagens publicadas antes do acompanhamento por job
+	LoteID    string      `json:"id_lote,omitempty"` // preenchido nas mensagens da geração em massa
+	Opcoes    FiltroItens `json:"opcoes"`
+}
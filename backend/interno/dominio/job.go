@@ -0,0 +1,25 @@
+package dominio
+
+import "time"
+
+// StatusJob é a etapa em que está uma solicitação de geração
+type StatusJob string
+
+const (
+	JobPendente    StatusJob = "pendente"    // aguardando ser reivindicado pela geração síncrona ou pelo worker
+	JobProcessando StatusJob = "processando" // em cálculo
+	JobConcluido   StatusJob = "concluido"   // recomendação persistida
+	JobFalhou      StatusJob = "falhou"      // erro registrado em Erro
+)
+
+// Job acompanha uma solicitação de geração de recomendação do início ao fim
+type Job struct {
+	ID             string     `json:"id_job"`
+	ClienteID      string     `json:"id_cliente"`
+	Status         StatusJob  `json:"status" swaggertype:"string" enums:"pendente,processando,concluido,falhou"`
+	CriadoEm       time.Time  `json:"criado_em"`
+	IniciadoEm     *time.Time `json:"iniciado_em,omitempty"`
+	FinalizadoEm   *time.Time `json:"finalizado_em,omitempty"`
+	Erro           string     `json:"erro,omitempty"`
+	RecomendacaoID string     `json:"id_recomendacao,omitempty"` // preenchido quando concluído
+}
@@ -0,0 +1,40 @@
+package dominio
+
+import "time"
+
+// StatusLote é a situação de uma geração em massa
+type StatusLote string
+
+const (
+	LoteEmAndamento StatusLote = "em_andamento"
+	LoteConcluido   StatusLote = "concluido" // todos os clientes foram processados (com ou sem falha)
+	LoteCancelado   StatusLote = "cancelado" // mensagens ainda não processadas são descartadas pelo worker
+)
+
+// Lote acompanha uma geração em massa disparada por POST /recomendacoes
+type Lote struct {
+	ID           string           `json:"id_lote"`
+	Status       StatusLote       `json:"status" swaggertype:"string" enums:"em_andamento,concluido,cancelado"`
+	Opcoes       FiltroItens      `json:"opcoes"`
+	Segmento     SegmentoClientes `json:"segmento"` // clientes incluídos no lote
+	Total        int              `json:"total"`
+	Publicados   int              `json:"publicados"`
+	Concluidos   int              `json:"concluidos"`
+	Falhos       int              `json:"falhos"`
+	CriadoEm     time.Time        `json:"criado_em"`
+	FinalizadoEm *time.Time       `json:"finalizado_em,omitempty"`
+
+	// PublicacaoFinalizada indica que todas as mensagens foram publicadas; até lá Total é uma estimativa
+	PublicacaoFinalizada bool `json:"publicacao_finalizada"`
+	// Cursor é o último cliente publicado, de onde a publicação é retomada após um reinício
+	Cursor string `json:"-"`
+
+	// calculados na consulta a partir dos contadores
+	Progresso           float64    `json:"progresso"`                      // fração processada (0 a 1)
+	EstimativaConclusao *time.Time `json:"estimativa_conclusao,omitempty"` // pelo ritmo médio até agora
+}
+
+// Processados soma os clientes concluídos e os que falharam
+func (l Lote) Processados() int {
+	return l.Concluidos + l.Falhos
+}
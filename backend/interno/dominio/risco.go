@@ -0,0 +1,136 @@
+package dominio
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PerfilRisco é o perfil de suitability do cliente
+type PerfilRisco int
+
+const (
+	PerfilIndefinido PerfilRisco = iota // perfil ausente ou não reconhecido
+	PerfilConservador
+	PerfilModerado
+	PerfilArrojado
+)
+
+// NivelRisco é a classificação de risco do produto
+type NivelRisco int
+
+const (
+	RiscoIndefinido NivelRisco = iota // classificação ausente ou não reconhecida
+	RiscoBaixo
+	RiscoMedio
+	RiscoAlto
+)
+
+var (
+	nomesPerfil = map[PerfilRisco]string{
+		PerfilConservador: "Conservador",
+		PerfilModerado:    "Moderado",
+		PerfilArrojado:    "Arrojado",
+	}
+	nomesRisco = map[NivelRisco]string{
+		RiscoBaixo: "Baixo",
+		RiscoMedio: "Médio",
+		RiscoAlto:  "Alto",
+	}
+)
+
+// removedorAcentos normaliza variações comuns de grafia vindas do banco ou de arquivos CSV
+var removedorAcentos = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u",
+	"ç", "c",
+)
+
+func normalizar(s string) string {
+	return removedorAcentos.Replace(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// ParsePerfilRisco converte o texto do banco ignorando caixa, espaços e acentos
+func ParsePerfilRisco(s string) (PerfilRisco, error) {
+	alvo := normalizar(s)
+	for perfil, nome := range nomesPerfil {
+		if normalizar(nome) == alvo {
+			return perfil, nil
+		}
+	}
+	return PerfilIndefinido, fmt.Errorf("perfil de risco inválido: %q", s)
+}
+
+// ParseNivelRisco converte o texto do banco ignorando caixa, espaços e acentos
+func ParseNivelRisco(s string) (NivelRisco, error) {
+	alvo := normalizar(s)
+	for nivel, nome := range nomesRisco {
+		if normalizar(nome) == alvo {
+			return nivel, nil
+		}
+	}
+	return RiscoIndefinido, fmt.Errorf("nível de risco inválido: %q", s)
+}
+
+func (p PerfilRisco) String() string { return nomesPerfil[p] }
+
+func (p PerfilRisco) Valido() bool {
+	_, ok := nomesPerfil[p]
+	return ok
+}
+
+// RiscoMaximo é o maior nível de risco adequado ao perfil (suitability)
+func (p PerfilRisco) RiscoMaximo() NivelRisco {
+	switch p {
+	case PerfilConservador:
+		return RiscoBaixo
+	case PerfilModerado:
+		return RiscoMedio
+	case PerfilArrojado:
+		return RiscoAlto
+	}
+	return RiscoIndefinido
+}
+
+func (p PerfilRisco) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *PerfilRisco) UnmarshalText(texto []byte) error {
+	if len(texto) == 0 {
+		*p = PerfilIndefinido
+		return nil
+	}
+	perfil, err := ParsePerfilRisco(string(texto))
+	if err != nil {
+		return err
+	}
+	*p = perfil
+	return nil
+}
+
+func (n NivelRisco) String() string { return nomesRisco[n] }
+
+func (n NivelRisco) Valido() bool {
+	_, ok := nomesRisco[n]
+	return ok
+}
+
+func (n NivelRisco) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+func (n *NivelRisco) UnmarshalText(texto []byte) error {
+	if len(texto) == 0 {
+		*n = RiscoIndefinido
+		return nil
+	}
+	nivel, err := ParseNivelRisco(string(texto))
+	if err != nil {
+		return err
+	}
+	*n = nivel
+	return nil
+}
@@ -0,0 +1,47 @@
+package dominio
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var formatoUUID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// SegmentoClientes restringe a geração em massa a um grupo de clientes.
+// Critérios vazios não filtram; os informados são combinados com E.
+type SegmentoClientes struct {
+	PerfisRisco      []PerfilRisco `json:"perfis_risco,omitempty" swaggertype:"array,string" example:"Moderado,Arrojado"`
+	PatrimonioMinimo *float64      `json:"patrimonio_minimo,omitempty" example:"100000"`
+	PatrimonioMaximo *float64      `json:"patrimonio_maximo,omitempty" example:"1000000"`
+	Objetivos        []string      `json:"objetivos,omitempty" example:"Aposentadoria"` // objetivo_investimento, sem diferenciar caixa
+	// SemRecomendacaoDias mantém apenas clientes sem recomendação gerada nos últimos N dias
+	SemRecomendacaoDias int      `json:"sem_recomendacao_dias,omitempty" example:"30"`
+	ClienteIDs          []string `json:"ids_clientes,omitempty"`
+}
+
+// Vazio indica que o segmento abrange todos os clientes
+func (s SegmentoClientes) Vazio() bool {
+	return len(s.PerfisRisco) == 0 && s.PatrimonioMinimo == nil && s.PatrimonioMaximo == nil &&
+		len(s.Objetivos) == 0 && s.SemRecomendacaoDias == 0 && len(s.ClienteIDs) == 0
+}
+
+// Validar rejeita critérios que resultariam em consulta inválida
+func (s SegmentoClientes) Validar() error {
+	for _, perfil := range s.PerfisRisco {
+		if !perfil.Valido() {
+			return fmt.Errorf("perfil de risco inválido no segmento: %q", perfil.String())
+		}
+	}
+	if s.PatrimonioMinimo != nil && s.PatrimonioMaximo != nil && *s.PatrimonioMinimo > *s.PatrimonioMaximo {
+		return fmt.Errorf("patrimônio mínimo maior que o máximo: %v > %v", *s.PatrimonioMinimo, *s.PatrimonioMaximo)
+	}
+	if s.SemRecomendacaoDias < 0 {
+		return fmt.Errorf("sem_recomendacao_dias não pode ser negativo: %d", s.SemRecomendacaoDias)
+	}
+	for _, id := range s.ClienteIDs {
+		if !formatoUUID.MatchString(id) {
+			return fmt.Errorf("id de cliente inválido no segmento: %q", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package dominio
+
+import "testing"
+
+func TestSegmentoClientesValidar(t *testing.T) {
+	minimo, maximo := 100000.0, 50000.0
+
+	casos := []struct {
+		nome     string
+		segmento SegmentoClientes
+		valido   bool
+		vazio    bool
+	}{
+		{"vazio", SegmentoClientes{}, true, true},
+		{"perfil válido", SegmentoClientes{PerfisRisco: []PerfilRisco{PerfilModerado}}, true, false},
+		{"perfil indefinido", SegmentoClientes{PerfisRisco: []PerfilRisco{PerfilIndefinido}}, false, false},
+		{"só patrimônio mínimo", SegmentoClientes{PatrimonioMinimo: &minimo}, true, false},
+		{"faixa de patrimônio invertida", SegmentoClientes{PatrimonioMinimo: &minimo, PatrimonioMaximo: &maximo}, false, false},
+		{"dias sem recomendação negativos", SegmentoClientes{SemRecomendacaoDias: -1}, false, false},
+		{"id de cliente válido", SegmentoClientes{ClienteIDs: []string{"3f2b8c1e-9d4a-4b7e-8c2f-1a5d6e7f8091"}}, true, false},
+		{"id de cliente malformado", SegmentoClientes{ClienteIDs: []string{"cliente-1"}}, false, false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if err := c.segmento.Validar(); (err == nil) != c.valido {
+				t.Errorf("válido = %v, esperado %v (erro: %v)", err == nil, c.valido, err)
+			}
+			if c.segmento.Vazio() != c.vazio {
+				t.Errorf("vazio = %v, esperado %v", c.segmento.Vazio(), c.vazio)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package dominio
+
+import "time"
+
+// Supressao impede que um produto descartado pelo cliente volte ao topo da recomendação
+// durante o período de carência. Pode ser encerrada antes pelo assessor.
+type Supressao struct {
+	ClienteID      string    `json:"id_cliente"`
+	ProdutoID      string    `json:"id_produto"`
+	RecomendacaoID string    `json:"id_recomendacao,omitempty"` // recomendação em que o item foi descartado
+	Inicio         time.Time `json:"inicio"`
+	ExpiraEm       time.Time `json:"expira_em"`
+}
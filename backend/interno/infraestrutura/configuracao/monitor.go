@@ -0,0 +1,103 @@
+package configuracao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"backend/interno/casodeuso"
+)
+
+// CarregarConfigPontuacao lê e valida a configuração de scoring de um arquivo JSON.
+// O arquivo é aplicado sobre os valores padrão, então blocos omitidos mantêm o padrão
+// em vez de ficarem zerados.
+func CarregarConfigPontuacao(caminho string) (casodeuso.ConfigPontuacao, error) {
+	cfg := casodeuso.ConfigPadrao()
+
+	data, err := os.ReadFile(caminho)
+	if err != nil {
+		return cfg, fmt.Errorf("erro ao ler arquivo de configuração: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("erro ao interpretar arquivo de configuração: %w", err)
+	}
+
+	if err := cfg.Validar(); err != nil {
+		return cfg, fmt.Errorf("configuração inválida: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// MonitorConfig verifica periodicamente o arquivo de configuração e aplica novas versões sem reiniciar a API
+type MonitorConfig struct {
+	caminho   string
+	intervalo time.Duration
+	aplicar   func(casodeuso.ConfigPontuacao) error
+
+	modificadoEm time.Time
+}
+
+// NovoMonitorConfig cria um monitor que chama aplicar sempre que o arquivo for alterado
+func NovoMonitorConfig(caminho string, intervalo time.Duration, aplicar func(casodeuso.ConfigPontuacao) error) *MonitorConfig {
+	return &MonitorConfig{
+		caminho:   caminho,
+		intervalo: intervalo,
+		aplicar:   aplicar,
+	}
+}
+
+// Iniciar faz a carga inicial e inicia a goroutine de recarga
+func (m *MonitorConfig) Iniciar(ctx context.Context) {
+	if _, err := os.Stat(m.caminho); err != nil {
+		slog.Warn("Arquivo de configuração de pontuação não encontrado, usando valores padrão", "arquivo", m.caminho, "erro", err)
+	}
+	m.recarregar()
+
+	go func() {
+		ticker := time.NewTicker(m.intervalo)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.recarregar()
+			}
+		}
+	}()
+
+	slog.Info("Monitor de configuração de pontuação iniciado", "arquivo", m.caminho, "intervalo", m.intervalo.String())
+}
+
+// recarregar aplica o arquivo apenas se ele mudou desde a última leitura.
+// Em caso de erro a configuração anterior continua valendo.
+func (m *MonitorConfig) recarregar() {
+	info, err := os.Stat(m.caminho)
+	if err != nil {
+		slog.Debug("Arquivo de configuração de pontuação indisponível, mantendo configuração atual", "arquivo", m.caminho, "erro", err)
+		return
+	}
+
+	if !info.ModTime().After(m.modificadoEm) {
+		return
+	}
+	// registra a leitura mesmo se falhar, para não repetir o mesmo erro a cada ciclo
+	m.modificadoEm = info.ModTime()
+
+	cfg, err := CarregarConfigPontuacao(m.caminho)
+	if err != nil {
+		slog.Error("Erro ao recarregar configuração de pontuação", "arquivo", m.caminho, "erro", err)
+		return
+	}
+
+	if err := m.aplicar(cfg); err != nil {
+		slog.Error("Erro ao aplicar configuração de pontuação", "arquivo", m.caminho, "erro", err)
+		return
+	}
+}
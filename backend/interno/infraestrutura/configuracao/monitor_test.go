@@ -0,0 +1,69 @@
+package configuracao
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"backend/interno/casodeuso"
+	"backend/interno/dominio"
+)
+
+func escreverConfig(t *testing.T, conteudo string) string {
+	t.Helper()
+	caminho := filepath.Join(t.TempDir(), "pontuacao.json")
+	if err := os.WriteFile(caminho, []byte(conteudo), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return caminho
+}
+
+func TestCarregarConfigPontuacaoParcial(t *testing.T) {
+	caminho := escreverConfig(t, `{
+		"versao": "v2",
+		"pesos": {"rentabilidade": 0.2, "perfil_compativel": {"Moderado": 0.3}},
+		"reranqueamento": {"top_n": 5}
+	}`)
+
+	cfg, err := CarregarConfigPontuacao(caminho)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	padrao := casodeuso.ConfigPadrao()
+	if cfg.Versao != "v2" || cfg.Pesos.Rentabilidade != 0.2 || cfg.Reranqueamento.TopN != 5 {
+		t.Errorf("valores do arquivo não aplicados: %+v", cfg)
+	}
+	if cfg.Pesos.PerfilCompativel[dominio.PerfilModerado] != 0.3 {
+		t.Errorf("peso de perfil do arquivo não aplicado: %v", cfg.Pesos.PerfilCompativel)
+	}
+	// o que o arquivo omite continua com o padrão
+	if cfg.Pesos.PerfilCompativel[dominio.PerfilConservador] != padrao.Pesos.PerfilCompativel[dominio.PerfilConservador] {
+		t.Errorf("peso de perfil omitido foi zerado: %v", cfg.Pesos.PerfilCompativel)
+	}
+	if cfg.Pesos.Interesse != padrao.Pesos.Interesse || cfg.Reranqueamento.Lambda != padrao.Reranqueamento.Lambda {
+		t.Errorf("campos omitidos foram zerados: pesos %+v, reranqueamento %+v", cfg.Pesos, cfg.Reranqueamento)
+	}
+	if len(cfg.Interesse.PesosTipo) != len(padrao.Interesse.PesosTipo) {
+		t.Errorf("bloco de interesse omitido foi zerado: %+v", cfg.Interesse)
+	}
+}
+
+func TestCarregarConfigPontuacaoInvalida(t *testing.T) {
+	casos := []struct {
+		nome     string
+		conteudo string
+	}{
+		{"json malformado", `{"versao": `},
+		{"valor fora do intervalo", `{"versao": "v2", "reranqueamento": {"lambda": 2}}`},
+		{"versão vazia", `{"versao": ""}`},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if _, err := CarregarConfigPontuacao(escreverConfig(t, c.conteudo)); err == nil {
+				t.Error("configuração deveria ser rejeitada")
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ package pubsub
 // Implementações disponíveis:
 // - GCPEventBus: Usa Google Cloud Pub/Sub (produção)
 type EventBus interface {
-	Publicar(topico string, payload interface{})
-	Assinar(topico string, handler func(payload interface{}))
+	Publicar(topico string, payload interface{}) error
+	// Assinar registra o handler do tópico. Um erro retornado pelo handler indica que a
+	// mensagem não foi processada e pode ser entregue de novo.
+	Assinar(topico string, handler func(payload interface{}) error)
 }
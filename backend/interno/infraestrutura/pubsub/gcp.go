@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"cloud.google.com/go/pubsub"
@@ -13,12 +14,22 @@ import (
 
 // GCPEventBus implementa EventBus usando Google Cloud Pub/Sub
 type GCPEventBus struct {
-	client   *pubsub.Client
-	ctx      context.Context
-	handlers map[string][]func(interface{})
-	mu       sync.RWMutex
-	subs     map[string]*pubsub.Subscription
-	ambiente string
+	client      *pubsub.Client
+	ctx         context.Context
+	handlers    map[string][]func(interface{}) error
+	mu          sync.RWMutex
+	subs        map[string]*pubsub.Subscription
+	assinaturas map[string]ConfigAssinatura
+	ambiente    string
+}
+
+// ConfigAssinatura controla o fluxo de consumo de uma subscription
+type ConfigAssinatura struct {
+	// MaxMensagensPendentes limita as mensagens recebidas e ainda não confirmadas. Quando
+	// definido, os handlers rodam antes do ack, para que o limite valha para o processamento.
+	MaxMensagensPendentes int
+	// Goroutines é o número de streams de recebimento; zero usa o padrão da biblioteca
+	Goroutines int
 }
 
 // NovoGCPEventBus cria uma nova instância do EventBus usando GCP Pub/Sub
@@ -29,34 +40,47 @@ func NovoGCPEventBus(ctx context.Context, projectID, ambiente string) (*GCPEvent
 	}
 
 	bus := &GCPEventBus{
-		client:   client,
-		ctx:      ctx,
-		handlers: make(map[string][]func(interface{})),
-		subs:     make(map[string]*pubsub.Subscription),
-		ambiente: ambiente,
+		client:      client,
+		ctx:         ctx,
+		handlers:    make(map[string][]func(interface{}) error),
+		subs:        make(map[string]*pubsub.Subscription),
+		assinaturas: make(map[string]ConfigAssinatura),
+		ambiente:    ambiente,
 	}
 
 	slog.Info("GCP Pub/Sub inicializado", "projectID", projectID, "ambiente", ambiente)
 	return bus, nil
 }
 
-// formatarTopico retorna o nome do tópico formatado com o ambiente
+// ambienteSemSufixo segue a convenção do Terraform (local.env_suffix em infra/main.tf)
+const ambienteSemSufixo = "prod"
+
+// formatarTopico retorna o nome do tópico formatado com o ambiente. Produção não leva
+// sufixo, como os recursos criados pelo Terraform.
 func (b *GCPEventBus) formatarTopico(topico string) string {
-	if b.ambiente == "" {
+	if b.ambiente == "" || b.ambiente == ambienteSemSufixo {
 		return topico
 	}
 	return fmt.Sprintf("%s-%s", topico, b.ambiente)
 }
 
-// Publicar publica um evento em um tópico do GCP Pub/Sub
-func (b *GCPEventBus) Publicar(nomeTopico string, payload interface{}) {
+// ConfigurarAssinatura define o controle de fluxo do tópico. Deve ser chamado antes de Assinar.
+func (b *GCPEventBus) ConfigurarAssinatura(nomeTopico string, cfg ConfigAssinatura) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.assinaturas[b.formatarTopico(nomeTopico)] = cfg
+}
+
+// Publicar publica um evento em um tópico do GCP Pub/Sub e aguarda a confirmação do servidor,
+// para que quem publica saiba se a mensagem será entregue
+func (b *GCPEventBus) Publicar(nomeTopico string, payload interface{}) error {
 	topico := b.formatarTopico(nomeTopico)
 
 	// Serializa o payload para JSON
 	data, err := json.Marshal(payload)
 	if err != nil {
 		slog.Error("Erro ao serializar payload", "topico", topico, "erro", err)
-		return
+		return err
 	}
 
 	// Obtém ou cria o tópico
@@ -66,14 +90,14 @@ func (b *GCPEventBus) Publicar(nomeTopico string, payload interface{}) {
 	exists, err := topic.Exists(b.ctx)
 	if err != nil {
 		slog.Error("Erro ao verificar existência do tópico", "topico", topico, "erro", err)
-		return
+		return err
 	}
 
 	if !exists {
 		topic, err = b.client.CreateTopic(b.ctx, topico)
 		if err != nil {
 			slog.Error("Erro ao criar tópico", "topico", topico, "erro", err)
-			return
+			return err
 		}
 		slog.Info("Tópico criado", "topico", topico)
 	}
@@ -83,19 +107,17 @@ func (b *GCPEventBus) Publicar(nomeTopico string, payload interface{}) {
 		Data: data,
 	})
 
-	// Aguarda confirmação de forma assíncrona
-	go func() {
-		id, err := result.Get(b.ctx)
-		if err != nil {
-			slog.Error("Erro ao publicar mensagem", "topico", topico, "erro", err)
-			return
-		}
-		slog.Info("Evento publicado", "topico", topico, "messageID", id)
-	}()
+	id, err := result.Get(b.ctx)
+	if err != nil {
+		slog.Error("Erro ao publicar mensagem", "topico", topico, "erro", err)
+		return err
+	}
+	slog.Info("Evento publicado", "topico", topico, "messageID", id)
+	return nil
 }
 
 // Assinar registra um handler para um tópico e inicia o consumo de mensagens
-func (b *GCPEventBus) Assinar(nomeTopico string, handler func(payload interface{})) {
+func (b *GCPEventBus) Assinar(nomeTopico string, handler func(payload interface{}) error) {
 	topico := b.formatarTopico(nomeTopico)
 
 	b.mu.Lock()
@@ -152,16 +174,61 @@ func (b *GCPEventBus) Assinar(nomeTopico string, handler func(payload interface{
 
 	b.mu.Lock()
 	b.subs[topico] = sub
+	cfg := b.assinaturas[topico]
 	b.mu.Unlock()
 
+	if cfg.MaxMensagensPendentes > 0 {
+		sub.ReceiveSettings.MaxOutstandingMessages = cfg.MaxMensagensPendentes
+	}
+	if cfg.Goroutines > 0 {
+		sub.ReceiveSettings.NumGoroutines = cfg.Goroutines
+	}
+
 	// Inicia o consumo de mensagens em uma goroutine
-	go b.consumirMensagens(topico, sub)
+	go b.consumirMensagens(topico, sub, cfg.MaxMensagensPendentes > 0)
 
 	slog.Info("Assinante registrado", "topico", topico, "subscription", subscriptionName)
+
+	if b.ambiente == ambienteSemSufixo {
+		b.drenarAssinaturaLegada(nomeTopico, topico, cfg)
+	}
 }
 
-// consumirMensagens processa mensagens de uma subscription
-func (b *GCPEventBus) consumirMensagens(topico string, sub *pubsub.Subscription) {
+// drenarAssinaturaLegada consome a subscription criada quando produção ainda usava o sufixo
+// "-prod" (por exemplo, gerar-recomendacao-prod-sub), para que mensagens publicadas antes do
+// deploy, ou por instâncias antigas durante a troca, não sejam abandonadas. A subscription
+// legada nunca é criada; depois de vazia ela pode ser removida.
+func (b *GCPEventBus) drenarAssinaturaLegada(nomeTopico, topico string, cfg ConfigAssinatura) {
+	nomeLegado := fmt.Sprintf("%s-%s-sub", nomeTopico, b.ambiente)
+
+	sub := b.client.Subscription(nomeLegado)
+	exists, err := sub.Exists(b.ctx)
+	if err != nil {
+		slog.Error("Erro ao verificar existência da subscription legada", "subscription", nomeLegado, "erro", err)
+		return
+	}
+	if !exists {
+		return
+	}
+
+	if cfg.MaxMensagensPendentes > 0 {
+		sub.ReceiveSettings.MaxOutstandingMessages = cfg.MaxMensagensPendentes
+	}
+	if cfg.Goroutines > 0 {
+		sub.ReceiveSettings.NumGoroutines = cfg.Goroutines
+	}
+
+	// as mensagens legadas vão para os mesmos handlers do tópico atual
+	go b.consumirMensagens(topico, sub, cfg.MaxMensagensPendentes > 0)
+
+	slog.Warn("Drenando subscription legada; remova-a quando não houver mensagens pendentes",
+		"subscription", nomeLegado, "topico", topico)
+}
+
+// consumirMensagens processa mensagens de uma subscription. Com aguardarHandlers, a mensagem só
+// é confirmada depois dos handlers, e o controle de fluxo da subscription limita o processamento;
+// se algum handler falhar, a mensagem recebe Nack para ser entregue de novo.
+func (b *GCPEventBus) consumirMensagens(topico string, sub *pubsub.Subscription, aguardarHandlers bool) {
 	err := sub.Receive(b.ctx, func(ctx context.Context, msg *pubsub.Message) {
 		// Tenta deserializar como string primeiro (caso comum: clienteID)
 		var payload interface{}
@@ -183,18 +250,36 @@ func (b *GCPEventBus) consumirMensagens(topico string, sub *pubsub.Subscription)
 		handlers, ok := b.handlers[topico]
 		b.mu.RUnlock()
 
+		var falhas atomic.Int32
 		if ok {
+			var wg sync.WaitGroup
 			for _, handler := range handlers {
+				wg.Add(1)
 				// Executa cada handler em sua própria goroutine
-				go func(h func(interface{}), p interface{}) {
+				go func(h func(interface{}) error, p interface{}) {
+					defer wg.Done()
 					defer func() {
 						if r := recover(); r != nil {
 							slog.Error("Panic no handler de evento", "erro", r, "topico", topico)
+							falhas.Add(1)
 						}
 					}()
-					h(p)
+					if err := h(p); err != nil {
+						slog.Error("Erro no handler de evento", "erro", err, "topico", topico, "messageID", msg.ID)
+						falhas.Add(1)
+					}
 				}(handler, payload)
 			}
+			if aguardarHandlers {
+				wg.Wait()
+			}
+		}
+
+		// Sem aguardar os handlers a mensagem já foi confirmada quando eles terminam
+		if aguardarHandlers && falhas.Load() > 0 {
+			msg.Nack()
+			slog.Warn("Mensagem devolvida para nova entrega", "topico", topico, "messageID", msg.ID)
+			return
 		}
 
 		// Confirma o processamento da mensagem
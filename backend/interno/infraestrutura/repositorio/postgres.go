@@ -1,9 +1,16 @@
 package repositorio
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"backend/interno/dominio"
 )
@@ -16,19 +23,46 @@ func NovoRepositorioPostgres(db *sql.DB) *RepositorioPostgres {
 	return &RepositorioPostgres{db: db}
 }
 
-func (r *RepositorioPostgres) ObterCliente(id string) (*dominio.Cliente, error) {
-	query := `SELECT id_cliente, perfil_risco, patrimonio_total_estimado FROM clientes WHERE id_cliente = $1`
+func (r *RepositorioPostgres) ObterCliente(ctx context.Context, id string) (*dominio.Cliente, error) {
+	query := `SELECT id_cliente, perfil_risco, COALESCE(patrimonio_total_estimado, 0), COALESCE(objetivo_investimento, '') FROM clientes WHERE id_cliente = $1`
 	var c dominio.Cliente
-	err := r.db.QueryRow(query, id).Scan(&c.ID, &c.PerfilRisco, &c.Patrimonio)
+	var perfil string
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&c.ID, &perfil, &c.Patrimonio, &c.ObjetivoInvestimento)
+	if err == sql.ErrNoRows || uuidInvalido(err) {
+		return nil, dominio.ErrClienteNaoEncontrado
+	}
 	if err != nil {
 		return nil, err
 	}
+	c.PerfilRisco = parsePerfil(perfil, c.ID)
 	return &c, nil
 }
 
-func (r *RepositorioPostgres) ListarProdutosAtivos() ([]dominio.Produto, error) {
-	query := `SELECT id_produto, nome_produto, risco_associado, rentabilidade_historica_12m, aplicacao_minima FROM produtos WHERE status_produto = 'Ativo'`
-	rows, err := r.db.Query(query)
+// parsePerfil valida o perfil lido do banco. Valores inválidos viram PerfilIndefinido, que o
+// filtro de suitability barra registrando o motivo na recomendação.
+func parsePerfil(valor, clienteID string) dominio.PerfilRisco {
+	perfil, err := dominio.ParsePerfilRisco(valor)
+	if err != nil {
+		slog.Warn("Perfil de risco inválido no cadastro do cliente", "erro", err, "cliente_id", clienteID)
+	}
+	return perfil
+}
+
+// parseRisco valida a classificação de risco lida do banco, com o mesmo tratamento de parsePerfil
+func parseRisco(valor, produtoID string) dominio.NivelRisco {
+	nivel, err := dominio.ParseNivelRisco(valor)
+	if err != nil {
+		slog.Warn("Risco inválido no cadastro do produto", "erro", err, "produto_id", produtoID)
+	}
+	return nivel
+}
+
+func (r *RepositorioPostgres) ListarProdutosAtivos(ctx context.Context) ([]dominio.Produto, error) {
+	query := `SELECT id_produto, nome_produto, COALESCE(tipo_produto, ''), COALESCE(risco_associado, ''),
+			COALESCE(rentabilidade_historica_12m, 0), COALESCE(rentabilidade_historica_36m, 0),
+			COALESCE(taxa_administracao, 0), COALESCE(aplicacao_minima, 0), COALESCE(liquidez, '')
+		FROM produtos WHERE status_produto = 'Ativo'`
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -37,41 +71,117 @@ func (r *RepositorioPostgres) ListarProdutosAtivos() ([]dominio.Produto, error)
 	var produtos []dominio.Produto
 	for rows.Next() {
 		var p dominio.Produto
-		if err := rows.Scan(&p.ID, &p.Nome, &p.RiscoAssociado, &p.Rentabilidade12m, &p.AplicacaoMinima); err != nil {
+		var risco string
+		if err := rows.Scan(&p.ID, &p.Nome, &p.TipoProduto, &risco, &p.Rentabilidade12m, &p.Rentabilidade36m,
+			&p.TaxaAdministracao, &p.AplicacaoMinima, &p.Liquidez); err != nil {
 			return nil, err
 		}
+		p.RiscoAssociado = parseRisco(risco, p.ID)
 		produtos = append(produtos, p)
 	}
 	return produtos, nil
 }
 
-func (r *RepositorioPostgres) VerificarPosseProduto(clienteID, produtoID string) (bool, error) {
-	var existe bool
-	// query otimizada usando EXISTS
-	query := `SELECT EXISTS(SELECT 1 FROM transacoes WHERE id_cliente=$1 AND id_produto=$2 AND tipo_transacao='Aplicacao')`
-	err := r.db.QueryRow(query, clienteID, produtoID).Scan(&existe)
-	return existe, err
+// ListarPosicoes calcula, em uma única consulta, o saldo líquido do cliente por produto.
+// Considera apenas transações concluídas e descarta produtos totalmente resgatados.
+func (r *RepositorioPostgres) ListarPosicoes(ctx context.Context, clienteID string) ([]dominio.Posicao, error) {
+	query := `SELECT t.id_produto, p.nome_produto, COALESCE(p.tipo_produto, ''), COALESCE(p.risco_associado, ''),
+			SUM(CASE t.tipo_transacao WHEN 'Aplicacao' THEN t.valor_transacao WHEN 'Resgate' THEN -t.valor_transacao ELSE 0 END) AS saldo
+		FROM transacoes t
+		JOIN produtos p ON p.id_produto = t.id_produto
+		WHERE t.id_cliente = $1 AND t.status_transacao = $2
+		GROUP BY t.id_produto, p.nome_produto, p.tipo_produto, p.risco_associado
+		HAVING SUM(CASE t.tipo_transacao WHEN 'Aplicacao' THEN t.valor_transacao WHEN 'Resgate' THEN -t.valor_transacao ELSE 0 END) > 0
+		ORDER BY saldo DESC`
+	rows, err := r.db.QueryContext(ctx, query, clienteID, dominio.StatusTransacaoConcluida)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posicoes []dominio.Posicao
+	for rows.Next() {
+		var p dominio.Posicao
+		var risco string
+		if err := rows.Scan(&p.ProdutoID, &p.NomeProduto, &p.TipoProduto, &risco, &p.Saldo); err != nil {
+			return nil, err
+		}
+		p.RiscoAssociado = parseRisco(risco, p.ProdutoID)
+		posicoes = append(posicoes, p)
+	}
+	return posicoes, rows.Err()
 }
 
-func (r *RepositorioPostgres) VerificarInteracaoRecente(clienteID, produtoID string) (bool, error) {
-	var existe bool
-	query := `SELECT EXISTS(SELECT 1 FROM interacoes WHERE id_cliente=$1 AND id_produto=$2)`
-	err := r.db.QueryRow(query, clienteID, produtoID).Scan(&existe)
-	return existe, err
+// ListarInteracoes retorna, em uma única consulta, as interações do cliente a partir da data informada
+func (r *RepositorioPostgres) ListarInteracoes(ctx context.Context, clienteID string, desde time.Time) ([]dominio.Interacao, error) {
+	query := `SELECT id_produto, COALESCE(tipo_interacao, ''), data_interacao, COALESCE(duracao_interacao_segundos, 0)
+		FROM interacoes WHERE id_cliente=$1 AND data_interacao >= $2`
+	rows, err := r.db.QueryContext(ctx, query, clienteID, desde)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interacoes []dominio.Interacao
+	for rows.Next() {
+		var i dominio.Interacao
+		if err := rows.Scan(&i.ProdutoID, &i.Tipo, &i.Data, &i.DuracaoSegundos); err != nil {
+			return nil, err
+		}
+		interacoes = append(interacoes, i)
+	}
+	return interacoes, rows.Err()
 }
 
-func (r *RepositorioPostgres) SalvarRecomendacao(clienteID string, itens []dominio.RecomendacaoItem) (string, error) {
+// ListarHistoricoProdutos busca aplicações e interações sem janela nem saldo em uma única consulta
+func (r *RepositorioPostgres) ListarHistoricoProdutos(ctx context.Context, clienteID string) (map[string]bool, map[string]bool, error) {
+	query := `SELECT DISTINCT 'aplicacao', id_produto FROM transacoes
+			WHERE id_cliente=$1 AND tipo_transacao='Aplicacao'
+		UNION
+		SELECT DISTINCT 'interacao', id_produto FROM interacoes WHERE id_cliente=$1`
+	rows, err := r.db.QueryContext(ctx, query, clienteID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	aplicados, interagidos := make(map[string]bool), make(map[string]bool)
+	for rows.Next() {
+		var origem, produtoID string
+		if err := rows.Scan(&origem, &produtoID); err != nil {
+			return nil, nil, err
+		}
+		if origem == "aplicacao" {
+			aplicados[produtoID] = true
+		} else {
+			interagidos[produtoID] = true
+		}
+	}
+	return aplicados, interagidos, rows.Err()
+}
+
+func (r *RepositorioPostgres) SalvarRecomendacao(ctx context.Context, resultado *dominio.ResultadoRecomendacao) (string, error) {
+	clienteID := resultado.ClienteID
+	itens := resultado.Recomendacoes
+
 	// converte o slice de structs para jsonb
 	jsonBytes, err := json.Marshal(itens)
 	if err != nil {
 		return "", err
 	}
 
-	query := `INSERT INTO recomendacoes (id_cliente, produtos_json) VALUES ($1, $2) RETURNING id`
+	// exclusões de elegibilidade ficam em coluna própria para auditoria de compliance
+	exclusoesJson, err := json.Marshal(resultado.Exclusoes)
+	if err != nil {
+		return "", err
+	}
+
+	query := `INSERT INTO recomendacoes (id_cliente, produtos_json, versao_config, exclusoes_json, estrategia, variante)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
 
 	var uuidGerado string
 	// executa insert e já retorna o uuid gerado pelo banco
-	err = r.db.QueryRow(query, clienteID, jsonBytes).Scan(&uuidGerado)
+	err = r.db.QueryRowContext(ctx, query, clienteID, jsonBytes, resultado.VersaoConfig, exclusoesJson, resultado.Estrategia, resultado.Variante).Scan(&uuidGerado)
 	if err != nil {
 		slog.Error("Erro de banco ao salvar recomendação", "erro", err, "cliente_id", clienteID)
 		return "", err
@@ -81,24 +191,59 @@ func (r *RepositorioPostgres) SalvarRecomendacao(clienteID string, itens []domin
 		"uuid", uuidGerado,
 		"cliente_id", clienteID,
 		"qtd_itens", len(itens),
+		"qtd_exclusoes", len(resultado.Exclusoes),
 	)
 
 	return uuidGerado, nil
 }
 
+// colunas comuns às consultas de recomendação; registros anteriores ao versionamento da
+// configuração não possuem versao_config
+const colunasRecomendacao = `id, id_cliente, produtos_json, COALESCE(versao_config, ''), COALESCE(exclusoes_json, '[]'),
+			COALESCE(estrategia, ''), COALESCE(variante, '')`
+
 func (r *RepositorioPostgres) BuscarUltimaRecomendacao(clienteID string) (*dominio.ResultadoRecomendacao, error) {
-	query := `SELECT id, id_cliente, produtos_json FROM recomendacoes WHERE id_cliente = $1 ORDER BY data_geracao DESC LIMIT 1`
-	var result dominio.ResultadoRecomendacao
-	var produtosJson []byte
+	query := `SELECT ` + colunasRecomendacao + `
+		FROM recomendacoes WHERE id_cliente = $1 ORDER BY data_geracao DESC LIMIT 1`
 
-	err := r.db.QueryRow(query, clienteID).Scan(&result.ID, &result.ClienteID, &produtosJson)
+	result, err := r.lerRecomendacao(r.db.QueryRow(query, clienteID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
+	if uuidInvalido(err) {
+		return nil, dominio.ErrClienteNaoEncontrado
+	}
 	if err != nil {
 		slog.Error("Erro de banco ao buscar recomendação", "erro", err, "cliente_id", clienteID)
 		return nil, err
 	}
+	return result, nil
+}
+
+// BuscarRecomendacao retorna uma recomendação persistida pelo seu uuid
+func (r *RepositorioPostgres) BuscarRecomendacao(id string) (*dominio.ResultadoRecomendacao, error) {
+	query := `SELECT ` + colunasRecomendacao + ` FROM recomendacoes WHERE id = $1`
+
+	result, err := r.lerRecomendacao(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows || uuidInvalido(err) {
+		return nil, dominio.ErrRecomendacaoNaoEncontrada
+	}
+	if err != nil {
+		slog.Error("Erro de banco ao buscar recomendação", "erro", err, "id_recomendacao", id)
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *RepositorioPostgres) lerRecomendacao(row *sql.Row) (*dominio.ResultadoRecomendacao, error) {
+	var result dominio.ResultadoRecomendacao
+	var produtosJson, exclusoesJson []byte
+
+	err := row.Scan(&result.ID, &result.ClienteID, &produtosJson, &result.VersaoConfig, &exclusoesJson,
+		&result.Estrategia, &result.Variante)
+	if err != nil {
+		return nil, err
+	}
 
 	err = json.Unmarshal(produtosJson, &result.Recomendacoes)
 	if err != nil {
@@ -106,12 +251,71 @@ func (r *RepositorioPostgres) BuscarUltimaRecomendacao(clienteID string) (*domin
 		return nil, err
 	}
 
+	err = json.Unmarshal(exclusoesJson, &result.Exclusoes)
+	if err != nil {
+		slog.Error("Erro ao fazer unmarshal das exclusões", "erro", err)
+		return nil, err
+	}
+
 	return &result, nil
 }
 
-func (r *RepositorioPostgres) ListarTodosClientes() ([]dominio.Cliente, error) {
-	query := `SELECT id_cliente, perfil_risco, patrimonio_total_estimado FROM clientes`
-	rows, err := r.db.Query(query)
+// uuidInvalido indica que o Postgres rejeitou o texto informado como uuid
+func uuidInvalido(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "22P02"
+}
+
+// filtroSegmento traduz o segmento em condições SQL sobre a tabela clientes. Os argumentos
+// recebidos já ocupam as primeiras posições, e os do segmento são numerados em seguida.
+func filtroSegmento(segmento dominio.SegmentoClientes, args []any) (string, []any) {
+	condicoes := []string{"TRUE"}
+	adicionar := func(condicao string, valor any) {
+		args = append(args, valor)
+		condicoes = append(condicoes, fmt.Sprintf(condicao, len(args)))
+	}
+
+	if len(segmento.PerfisRisco) > 0 {
+		perfis := make([]string, len(segmento.PerfisRisco))
+		for i, perfil := range segmento.PerfisRisco {
+			perfis[i] = strings.ToLower(perfil.String())
+		}
+		adicionar("lower(trim(perfil_risco)) = ANY($%d)", pq.Array(perfis))
+	}
+	if segmento.PatrimonioMinimo != nil {
+		adicionar("COALESCE(patrimonio_total_estimado, 0) >= $%d", *segmento.PatrimonioMinimo)
+	}
+	if segmento.PatrimonioMaximo != nil {
+		adicionar("COALESCE(patrimonio_total_estimado, 0) <= $%d", *segmento.PatrimonioMaximo)
+	}
+	if len(segmento.Objetivos) > 0 {
+		objetivos := make([]string, len(segmento.Objetivos))
+		for i, objetivo := range segmento.Objetivos {
+			objetivos[i] = strings.ToLower(strings.TrimSpace(objetivo))
+		}
+		adicionar("lower(trim(objetivo_investimento)) = ANY($%d)", pq.Array(objetivos))
+	}
+	if segmento.SemRecomendacaoDias > 0 {
+		adicionar(`NOT EXISTS (SELECT 1 FROM recomendacoes r WHERE r.id_cliente = clientes.id_cliente
+			AND r.data_geracao >= NOW() - make_interval(days => $%d))`, segmento.SemRecomendacaoDias)
+	}
+	if len(segmento.ClienteIDs) > 0 {
+		adicionar("id_cliente = ANY($%d::uuid[])", pq.Array(segmento.ClienteIDs))
+	}
+	return strings.Join(condicoes, " AND "), args
+}
+
+// ListarClientesApos retorna a próxima página de clientes do segmento ordenada por id, sem OFFSET,
+// para que a geração em massa percorra a base com memória limitada e possa ser retomada
+func (r *RepositorioPostgres) ListarClientesApos(cursor string, limite int, segmento dominio.SegmentoClientes) ([]dominio.Cliente, error) {
+	// cursor vazio vira NULL: o Postgres não garante a ordem de avaliação do OR, e ''::uuid falharia
+	filtro, args := filtroSegmento(segmento, []any{sql.NullString{String: cursor, Valid: cursor != ""}, limite})
+	query := `SELECT id_cliente, perfil_risco, COALESCE(patrimonio_total_estimado, 0), COALESCE(objetivo_investimento, '')
+		FROM clientes
+		WHERE ($1::uuid IS NULL OR id_cliente > $1::uuid) AND ` + filtro + `
+		ORDER BY id_cliente
+		LIMIT $2`
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -120,10 +324,462 @@ func (r *RepositorioPostgres) ListarTodosClientes() ([]dominio.Cliente, error) {
 	var clientes []dominio.Cliente
 	for rows.Next() {
 		var c dominio.Cliente
-		if err := rows.Scan(&c.ID, &c.PerfilRisco, &c.Patrimonio); err != nil {
+		var perfil string
+		if err := rows.Scan(&c.ID, &perfil, &c.Patrimonio, &c.ObjetivoInvestimento); err != nil {
 			return nil, err
 		}
+		c.PerfilRisco = parsePerfil(perfil, c.ID)
 		clientes = append(clientes, c)
 	}
-	return clientes, nil
+	return clientes, rows.Err()
+}
+
+// ContarClientes retorna o total de clientes do segmento, usado como estimativa do tamanho do lote
+func (r *RepositorioPostgres) ContarClientes(segmento dominio.SegmentoClientes) (int, error) {
+	filtro, args := filtroSegmento(segmento, nil)
+	var total int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM clientes WHERE `+filtro, args...).Scan(&total)
+	return total, err
+}
+
+// ListarTransacoesConcluidas carrega o histórico de transações em ordem cronológica (avaliação offline)
+func (r *RepositorioPostgres) ListarTransacoesConcluidas() ([]dominio.Transacao, error) {
+	query := `SELECT id_cliente, id_produto, COALESCE(tipo_transacao, ''), COALESCE(valor_transacao, 0), data_transacao
+		FROM transacoes WHERE status_transacao = $1 AND data_transacao IS NOT NULL
+		ORDER BY data_transacao`
+	rows, err := r.db.Query(query, dominio.StatusTransacaoConcluida)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transacoes []dominio.Transacao
+	for rows.Next() {
+		var t dominio.Transacao
+		if err := rows.Scan(&t.ClienteID, &t.ProdutoID, &t.Tipo, &t.Valor, &t.Data); err != nil {
+			return nil, err
+		}
+		transacoes = append(transacoes, t)
+	}
+	return transacoes, rows.Err()
+}
+
+// ListarInteracoesPorCliente carrega todas as interações agrupadas por cliente (avaliação offline)
+func (r *RepositorioPostgres) ListarInteracoesPorCliente() (map[string][]dominio.Interacao, error) {
+	query := `SELECT id_cliente, id_produto, COALESCE(tipo_interacao, ''), data_interacao, COALESCE(duracao_interacao_segundos, 0)
+		FROM interacoes WHERE data_interacao IS NOT NULL
+		ORDER BY data_interacao`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	porCliente := make(map[string][]dominio.Interacao)
+	for rows.Next() {
+		var clienteID string
+		var i dominio.Interacao
+		if err := rows.Scan(&clienteID, &i.ProdutoID, &i.Tipo, &i.Data, &i.DuracaoSegundos); err != nil {
+			return nil, err
+		}
+		porCliente[clienteID] = append(porCliente[clienteID], i)
+	}
+	return porCliente, rows.Err()
+}
+
+// SalvarFeedback grava os eventos em uma única transação
+func (r *RepositorioPostgres) SalvarFeedback(eventos []dominio.EventoFeedback) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO feedback_recomendacoes (id_recomendacao, id_produto, tipo_evento, data_evento, regras)
+		VALUES ($1, $2, $3, $4, $5)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range eventos {
+		if _, err := stmt.Exec(e.RecomendacaoID, e.ProdutoID, string(e.Tipo), e.Data, pq.Array(e.Regras)); err != nil {
+			slog.Error("Erro de banco ao salvar feedback", "erro", err, "id_recomendacao", e.RecomendacaoID)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// AgregarFeedback conta, por produto e por regra, os itens distintos que receberam cada tipo de evento
+func (r *RepositorioPostgres) AgregarFeedback(desde time.Time) (*dominio.MetricasFeedback, error) {
+	porProduto, err := r.agregarFeedback(`SELECT id_produto::text, tipo_evento, COUNT(DISTINCT (id_recomendacao, id_produto))
+		FROM feedback_recomendacoes WHERE data_evento >= $1
+		GROUP BY 1, 2 ORDER BY 1`, desde)
+	if err != nil {
+		return nil, err
+	}
+
+	porRegra, err := r.agregarFeedback(`SELECT regra, tipo_evento, COUNT(DISTINCT (id_recomendacao, id_produto))
+		FROM feedback_recomendacoes, unnest(regras) AS regra WHERE data_evento >= $1
+		GROUP BY 1, 2 ORDER BY 1`, desde)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dominio.MetricasFeedback{PorProduto: porProduto, PorRegra: porRegra}, nil
+}
+
+// agregarFeedback lê linhas (chave, tipo, quantidade) e monta uma métrica por chave, na ordem da consulta
+func (r *RepositorioPostgres) agregarFeedback(query string, desde time.Time) ([]dominio.MetricaFeedback, error) {
+	rows, err := r.db.Query(query, desde)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	metricas := []dominio.MetricaFeedback{}
+	for rows.Next() {
+		var chave, tipo string
+		var quantidade int
+		if err := rows.Scan(&chave, &tipo, &quantidade); err != nil {
+			return nil, err
+		}
+		if len(metricas) == 0 || metricas[len(metricas)-1].Chave != chave {
+			metricas = append(metricas, dominio.MetricaFeedback{Chave: chave})
+		}
+		m := &metricas[len(metricas)-1]
+		switch dominio.TipoFeedback(tipo) {
+		case dominio.FeedbackVisualizado:
+			m.Visualizados = quantidade
+		case dominio.FeedbackClicado:
+			m.Clicados = quantidade
+		case dominio.FeedbackAceito:
+			m.Aceitos = quantidade
+		case dominio.FeedbackDescartado:
+			m.Descartados = quantidade
+		}
+	}
+	for i := range metricas {
+		metricas[i].CalcularTaxa()
+	}
+	return metricas, rows.Err()
+}
+
+// SalvarSupressoes grava as carências de produtos descartados em uma única transação
+func (r *RepositorioPostgres) SalvarSupressoes(supressoes []dominio.Supressao) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO supressoes_produto (id_cliente, id_produto, id_recomendacao, data_inicio, expira_em)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, s := range supressoes {
+		if _, err := stmt.Exec(s.ClienteID, s.ProdutoID, s.RecomendacaoID, s.Inicio, s.ExpiraEm); err != nil {
+			slog.Error("Erro de banco ao salvar supressão", "erro", err, "cliente_id", s.ClienteID, "id_produto", s.ProdutoID)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListarSupressoesAtivas retorna as carências não encerradas pelo assessor e ainda não expiradas
+func (r *RepositorioPostgres) ListarSupressoesAtivas(ctx context.Context, clienteID string, em time.Time) ([]dominio.Supressao, error) {
+	query := `SELECT id_cliente, id_produto, COALESCE(id_recomendacao::text, ''), data_inicio, expira_em
+		FROM supressoes_produto
+		WHERE id_cliente = $1 AND encerrada_em IS NULL AND expira_em > $2
+		ORDER BY expira_em`
+	rows, err := r.db.QueryContext(ctx, query, clienteID, em)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	supressoes := []dominio.Supressao{}
+	for rows.Next() {
+		var s dominio.Supressao
+		if err := rows.Scan(&s.ClienteID, &s.ProdutoID, &s.RecomendacaoID, &s.Inicio, &s.ExpiraEm); err != nil {
+			return nil, err
+		}
+		supressoes = append(supressoes, s)
+	}
+	return supressoes, rows.Err()
+}
+
+// EncerrarSupressoes registra quem encerrou as carências ativas do produto; o histórico é mantido
+func (r *RepositorioPostgres) EncerrarSupressoes(clienteID, produtoID, responsavel string, em time.Time) (int64, error) {
+	query := `UPDATE supressoes_produto SET encerrada_em = $4, encerrada_por = $3
+		WHERE id_cliente = $1 AND id_produto = $2 AND encerrada_em IS NULL AND expira_em > $4`
+	res, err := r.db.Exec(query, clienteID, produtoID, responsavel, em)
+	if uuidInvalido(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// CriarJob registra uma solicitação de geração como pendente
+func (r *RepositorioPostgres) CriarJob(ctx context.Context, clienteID string) (*dominio.Job, error) {
+	job := dominio.Job{ClienteID: clienteID, Status: dominio.JobPendente}
+	query := `INSERT INTO jobs_geracao (id_cliente, status) VALUES ($1, $2) RETURNING id_job, criado_em`
+	if err := r.db.QueryRowContext(ctx, query, clienteID, string(job.Status)).Scan(&job.ID, &job.CriadoEm); err != nil {
+		slog.Error("Erro de banco ao criar job", "erro", err, "cliente_id", clienteID)
+		return nil, err
+	}
+	return &job, nil
+}
+
+// BuscarJob retorna o job pelo seu uuid
+func (r *RepositorioPostgres) BuscarJob(id string) (*dominio.Job, error) {
+	query := `SELECT id_job, id_cliente, status, criado_em, iniciado_em, finalizado_em,
+			COALESCE(erro, ''), COALESCE(id_recomendacao::text, '')
+		FROM jobs_geracao WHERE id_job = $1`
+	var job dominio.Job
+	var status string
+	var iniciadoEm, finalizadoEm sql.NullTime
+	err := r.db.QueryRow(query, id).Scan(&job.ID, &job.ClienteID, &status, &job.CriadoEm, &iniciadoEm, &finalizadoEm,
+		&job.Erro, &job.RecomendacaoID)
+	if err == sql.ErrNoRows || uuidInvalido(err) {
+		return nil, dominio.ErrJobNaoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+	job.Status = dominio.StatusJob(status)
+	if iniciadoEm.Valid {
+		job.IniciadoEm = &iniciadoEm.Time
+	}
+	if finalizadoEm.Valid {
+		job.FinalizadoEm = &finalizadoEm.Time
+	}
+	return &job, nil
+}
+
+// ReivindicarJob faz a transição pendente -> processando em um único UPDATE condicional, de
+// modo que a geração síncrona e o worker nunca processem o mesmo job
+func (r *RepositorioPostgres) ReivindicarJob(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE jobs_geracao SET status = 'processando', iniciado_em = NOW()
+		WHERE id_job = $1 AND status = 'pendente'`
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		slog.Error("Erro de banco ao reivindicar job", "erro", err, "id_job", id)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// AtualizarJob grava a transição de status e o carimbo de tempo correspondente
+func (r *RepositorioPostgres) AtualizarJob(id string, status dominio.StatusJob, recomendacaoID, erro string) error {
+	query := `UPDATE jobs_geracao SET status = $2,
+			iniciado_em = CASE WHEN $2 = 'processando' THEN NOW() ELSE iniciado_em END,
+			finalizado_em = CASE WHEN $2 IN ('concluido', 'falhou') THEN NOW() ELSE finalizado_em END,
+			id_recomendacao = NULLIF($3, '')::uuid,
+			erro = NULLIF($4, '')
+		WHERE id_job = $1`
+	_, err := r.db.Exec(query, id, string(status), recomendacaoID, erro)
+	if err != nil {
+		slog.Error("Erro de banco ao atualizar job", "erro", err, "id_job", id, "status", status)
+	}
+	return err
+}
+
+// CriarLote registra uma geração em massa já reservada para a instância que vai publicá-la
+func (r *RepositorioPostgres) CriarLote(total int, opcoes dominio.FiltroItens, segmento dominio.SegmentoClientes, reserva time.Duration) (*dominio.Lote, error) {
+	opcoesJson, err := json.Marshal(opcoes)
+	if err != nil {
+		return nil, err
+	}
+	segmentoJson, err := json.Marshal(segmento)
+	if err != nil {
+		return nil, err
+	}
+
+	lote := dominio.Lote{Status: dominio.LoteEmAndamento, Opcoes: opcoes, Segmento: segmento, Total: total}
+	query := `INSERT INTO lotes_geracao (status, total, opcoes_json, segmento_json, publicacao_expira_em)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5)) RETURNING id_lote, criado_em`
+	err = r.db.QueryRow(query, string(lote.Status), total, opcoesJson, segmentoJson, reserva.Seconds()).Scan(&lote.ID, &lote.CriadoEm)
+	if err != nil {
+		slog.Error("Erro de banco ao criar lote", "erro", err)
+		return nil, err
+	}
+	return &lote, nil
+}
+
+// colunas comuns às consultas de lote
+const colunasLote = `id_lote, status, COALESCE(opcoes_json, '{}'), COALESCE(segmento_json, '{}'), total, publicados, concluidos, falhos,
+			criado_em, finalizado_em, publicacao_finalizada, COALESCE(cursor_cliente::text, '')`
+
+// scanner é atendido tanto por *sql.Row quanto por *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func lerLote(row scanner) (*dominio.Lote, error) {
+	var lote dominio.Lote
+	var status string
+	var opcoesJson, segmentoJson []byte
+	var finalizadoEm sql.NullTime
+	err := row.Scan(&lote.ID, &status, &opcoesJson, &segmentoJson, &lote.Total, &lote.Publicados, &lote.Concluidos, &lote.Falhos,
+		&lote.CriadoEm, &finalizadoEm, &lote.PublicacaoFinalizada, &lote.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	lote.Status = dominio.StatusLote(status)
+	if finalizadoEm.Valid {
+		lote.FinalizadoEm = &finalizadoEm.Time
+	}
+	if err := json.Unmarshal(opcoesJson, &lote.Opcoes); err != nil {
+		slog.Error("Erro ao fazer unmarshal das opções do lote", "erro", err)
+		return nil, err
+	}
+	if err := json.Unmarshal(segmentoJson, &lote.Segmento); err != nil {
+		slog.Error("Erro ao fazer unmarshal do segmento do lote", "erro", err)
+		return nil, err
+	}
+	return &lote, nil
+}
+
+// BuscarLote retorna o lote com seus contadores
+func (r *RepositorioPostgres) BuscarLote(id string) (*dominio.Lote, error) {
+	lote, err := lerLote(r.db.QueryRow(`SELECT `+colunasLote+` FROM lotes_geracao WHERE id_lote = $1`, id))
+	if err == sql.ErrNoRows || uuidInvalido(err) {
+		return nil, dominio.ErrLoteNaoEncontrado
+	}
+	return lote, err
+}
+
+// AtualizarPublicacaoLote grava o checkpoint da publicação (quantidade e último cliente) e renova a reserva
+func (r *RepositorioPostgres) AtualizarPublicacaoLote(id string, publicados int, cursor string, reserva time.Duration) error {
+	query := `UPDATE lotes_geracao SET publicados = $2, cursor_cliente = NULLIF($3, '')::uuid,
+			publicacao_expira_em = NOW() + make_interval(secs => $4)
+		WHERE id_lote = $1`
+	_, err := r.db.Exec(query, id, publicados, cursor, reserva.Seconds())
+	return err
+}
+
+// LiberarPublicacaoLote encerra a reserva da instância que parou de publicar; o próximo
+// ReivindicarLotesPendentes, desta ou de outra instância, retoma o lote do último checkpoint
+func (r *RepositorioPostgres) LiberarPublicacaoLote(id string) error {
+	query := `UPDATE lotes_geracao SET publicacao_expira_em = NOW()
+		WHERE id_lote = $1 AND status = 'em_andamento' AND NOT publicacao_finalizada`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// FinalizarPublicacaoLote troca a estimativa de total pelo número real de mensagens publicadas
+// e conclui o lote se os workers já processaram todas
+func (r *RepositorioPostgres) FinalizarPublicacaoLote(id string, publicados int) error {
+	query := `UPDATE lotes_geracao SET publicados = $2, total = $2, publicacao_finalizada = TRUE, publicacao_expira_em = NULL,
+			status = CASE WHEN status = 'em_andamento' AND concluidos + falhos >= $2 THEN 'concluido' ELSE status END,
+			finalizado_em = CASE WHEN status = 'em_andamento' AND concluidos + falhos >= $2 THEN NOW() ELSE finalizado_em END
+		WHERE id_lote = $1`
+	_, err := r.db.Exec(query, id, publicados)
+	return err
+}
+
+// ReivindicarLotesPendentes reserva, de forma atômica, os lotes cuja publicação foi interrompida.
+// A reserva expirada indica que a instância responsável parou sem finalizar a publicação.
+func (r *RepositorioPostgres) ReivindicarLotesPendentes(reserva time.Duration) ([]dominio.Lote, error) {
+	query := `UPDATE lotes_geracao SET publicacao_expira_em = NOW() + make_interval(secs => $1)
+		WHERE status = 'em_andamento' AND NOT publicacao_finalizada
+			AND (publicacao_expira_em IS NULL OR publicacao_expira_em < NOW())
+		RETURNING ` + colunasLote
+	rows, err := r.db.Query(query, reserva.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lotes []dominio.Lote
+	for rows.Next() {
+		lote, err := lerLote(rows)
+		if err != nil {
+			return nil, err
+		}
+		lotes = append(lotes, *lote)
+	}
+	return lotes, rows.Err()
+}
+
+// RegistrarResultadoLote grava o resultado do cliente em resultados_lote e só ajusta os
+// contadores quando a linha é inserida ou quando uma falha vira sucesso (reprocessamento
+// após nack), de modo que mensagens reentregues não contem de novo. Os contadores mudam na
+// mesma transação, de forma atômica, já que vários workers atualizam o mesmo lote.
+// O lote só é concluído depois que a publicação terminou e o total deixou de ser estimativa.
+func (r *RepositorioPostgres) RegistrarResultadoLote(id, clienteID string, sucesso bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO resultados_lote (id_lote, id_cliente, sucesso) VALUES ($1, $2, $3)
+		ON CONFLICT (id_lote, id_cliente) DO NOTHING`, id, clienteID, sucesso)
+	if err != nil {
+		slog.Error("Erro de banco ao registrar resultado do lote", "erro", err, "id_lote", id, "cliente_id", clienteID)
+		return err
+	}
+	inseridos, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	var concluidos, falhos int
+	switch {
+	case inseridos == 1 && sucesso:
+		concluidos = 1
+	case inseridos == 1:
+		falhos = 1
+	case sucesso:
+		res, err := tx.Exec(`UPDATE resultados_lote SET sucesso = TRUE, registrado_em = NOW()
+			WHERE id_lote = $1 AND id_cliente = $2 AND NOT sucesso`, id, clienteID)
+		if err != nil {
+			return err
+		}
+		if corrigidos, err := res.RowsAffected(); err != nil || corrigidos == 0 {
+			return err
+		}
+		concluidos, falhos = 1, -1
+	default:
+		// falha repetida de um cliente já registrado
+		return nil
+	}
+
+	query := `UPDATE lotes_geracao SET
+			concluidos = concluidos + $2,
+			falhos = falhos + $3,
+			status = CASE WHEN status = 'em_andamento' AND publicacao_finalizada AND concluidos + falhos + $2 + $3 >= total
+				THEN 'concluido' ELSE status END,
+			finalizado_em = CASE WHEN status = 'em_andamento' AND publicacao_finalizada AND concluidos + falhos + $2 + $3 >= total
+				THEN NOW() ELSE finalizado_em END
+		WHERE id_lote = $1`
+	if _, err := tx.Exec(query, id, concluidos, falhos); err != nil {
+		slog.Error("Erro de banco ao atualizar contadores do lote", "erro", err, "id_lote", id)
+		return err
+	}
+	return tx.Commit()
+}
+
+// CancelarLote interrompe um lote em andamento
+func (r *RepositorioPostgres) CancelarLote(id string) (bool, error) {
+	res, err := r.db.Exec(`UPDATE lotes_geracao SET status = 'cancelado', finalizado_em = NOW()
+		WHERE id_lote = $1 AND status = 'em_andamento'`, id)
+	if uuidInvalido(err) {
+		return false, dominio.ErrLoteNaoEncontrado
+	}
+	if err != nil {
+		return false, err
+	}
+	afetados, err := res.RowsAffected()
+	return afetados > 0, err
 }
@@ -1,18 +1,26 @@
 package worker
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 
 	"backend/interno/casodeuso"
+	"backend/interno/dominio"
 	"backend/interno/infraestrutura/pubsub"
 )
 
-const TopicoGerarRecomendacao = "gerar-recomendacao"
-
 type WorkerRecomendacao struct {
 	servico *casodeuso.ServicoRecomendacao
 	bus     pubsub.EventBus
+
+	// interativas conta as gerações individuais em processamento; enquanto houver alguma,
+	// as mensagens de massa aguardam
+	mu          sync.Mutex
+	livre       *sync.Cond
+	interativas int
 }
 
 func NovoWorkerRecomendacao(servico *casodeuso.ServicoRecomendacao, bus pubsub.EventBus) *WorkerRecomendacao {
@@ -20,46 +28,113 @@ func NovoWorkerRecomendacao(servico *casodeuso.ServicoRecomendacao, bus pubsub.E
 		servico: servico,
 		bus:     bus,
 	}
+	worker.livre = sync.NewCond(&worker.mu)
 	return worker
 }
 
-// Iniciar registra o worker como assinante do tópico e inicia a goroutine de consumo
+// Iniciar registra o worker como assinante dos tópicos e inicia as goroutines de consumo
 func (w *WorkerRecomendacao) Iniciar() {
-	slog.Info("Inicializando Worker de Recomendação", "topico_alvo", TopicoGerarRecomendacao)
+	slog.Info("Inicializando Worker de Recomendação",
+		"topico_alvo", dominio.TopicoGerarRecomendacao,
+		"topico_massa", dominio.TopicoGerarRecomendacaoMassa)
 
 	// A função Assinar inicia o processamento em background (goroutine)
-	w.bus.Assinar(TopicoGerarRecomendacao, w.processarEvento)
+	w.bus.Assinar(dominio.TopicoGerarRecomendacao, w.processarInterativo)
+	w.bus.Assinar(dominio.TopicoGerarRecomendacaoMassa, w.processarMassa)
 
 	slog.Info("Worker de recomendação iniciado com sucesso")
 }
 
-func (w *WorkerRecomendacao) processarEvento(payload interface{}) {
+// processarInterativo trata as gerações individuais, que têm prioridade sobre as de massa
+func (w *WorkerRecomendacao) processarInterativo(payload interface{}) error {
+	w.mu.Lock()
+	w.interativas++
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		w.interativas--
+		if w.interativas == 0 {
+			w.livre.Broadcast()
+		}
+		w.mu.Unlock()
+	}()
+
+	return w.processarEvento(payload)
+}
+
+// processarMassa aguarda as gerações individuais em andamento antes de processar a mensagem do lote.
+// Com o controle de fluxo da subscription de massa, a espera também segura o recebimento de novas mensagens.
+func (w *WorkerRecomendacao) processarMassa(payload interface{}) error {
+	w.mu.Lock()
+	for w.interativas > 0 {
+		w.livre.Wait()
+	}
+	w.mu.Unlock()
+
+	return w.processarEvento(payload)
+}
+
+// processarEvento só retorna erro quando vale tentar de novo: payloads inválidos e clientes
+// inexistentes não melhoram com uma nova entrega e são descartados
+func (w *WorkerRecomendacao) processarEvento(payload interface{}) error {
 	slog.Info("Mensagem recebida no worker de recomendação")
 
-	clienteID, ok := payload.(string)
-	if !ok {
-		slog.Error("Payload inválido recebido no worker: esperava string (clienteID)",
+	solicitacao, err := decodificarSolicitacao(payload)
+	if err != nil {
+		slog.Error("Payload inválido recebido no worker",
+			"erro", err,
 			"payload_type", fmt.Sprintf("%T", payload),
 			"payload_value", payload)
-		return
+		return nil
 	}
 
+	clienteID := solicitacao.ClienteID
 	if clienteID == "" {
 		slog.Warn("Recebido clienteID vazio no worker")
-		return
+		return nil
 	}
 
 	slog.Info("Iniciando processamento assíncrono para cliente", "cliente_id", clienteID)
 
-	resultado, err := w.servico.Executar(clienteID)
+	resultado, err := w.servico.ProcessarSolicitacao(solicitacao)
 	if err != nil {
 		slog.Error("Erro ao processar recomendação no worker",
 			"erro", err,
-			"cliente_id", clienteID)
-		return
+			"cliente_id", clienteID,
+			"id_job", solicitacao.JobID)
+		if errors.Is(err, dominio.ErrClienteNaoEncontrado) {
+			return nil
+		}
+		return err
+	}
+	if resultado == nil {
+		// lote cancelado: a mensagem é descartada
+		return nil
 	}
 
 	slog.Info("Recomendação processada com sucesso via worker",
 		"cliente_id", clienteID,
 		"recomendacoes_geradas", len(resultado.Recomendacoes))
+	return nil
+}
+
+// decodificarSolicitacao aceita o formato antigo (apenas o clienteID como string)
+// e o atual, com os filtros da geração
+func decodificarSolicitacao(payload interface{}) (dominio.SolicitacaoGeracao, error) {
+	var solicitacao dominio.SolicitacaoGeracao
+
+	if clienteID, ok := payload.(string); ok {
+		solicitacao.ClienteID = clienteID
+		return solicitacao, nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return solicitacao, err
+	}
+	if err := json.Unmarshal(data, &solicitacao); err != nil {
+		return solicitacao, err
+	}
+	return solicitacao, nil
 }
@@ -0,0 +1,177 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/interno/casodeuso"
+	"backend/interno/dominio"
+)
+
+// busFake guarda os handlers por tópico; as mensagens são entregues chamando-os diretamente
+type busFake struct {
+	handlers map[string]func(payload interface{}) error
+}
+
+func (b *busFake) Publicar(topico string, payload interface{}) error { return nil }
+
+func (b *busFake) Assinar(topico string, handler func(payload interface{}) error) {
+	b.handlers[topico] = handler
+}
+
+// repositorioFake só atende ObterCliente, a primeira consulta da geração; os demais métodos
+// caem na interface embutida (nil) e entram em pânico
+type repositorioFake struct {
+	dominio.RepositorioDados
+
+	erro    error
+	entrou  chan string   // avisa que a geração do cliente começou
+	liberar chan struct{} // segura a geração até ser fechado
+}
+
+func (r *repositorioFake) ObterCliente(ctx context.Context, id string) (*dominio.Cliente, error) {
+	if r.entrou != nil {
+		r.entrou <- id
+	}
+	if r.liberar != nil {
+		<-r.liberar
+	}
+	return nil, r.erro
+}
+
+func novoWorkerTeste(repo *repositorioFake) (*WorkerRecomendacao, *busFake) {
+	bus := &busFake{handlers: make(map[string]func(payload interface{}) error)}
+	servico := casodeuso.NovoServicoRecomendacao(repo, bus)
+	return NovoWorkerRecomendacao(servico, bus), bus
+}
+
+func TestIniciarAssinaTopicos(t *testing.T) {
+	w, bus := novoWorkerTeste(&repositorioFake{erro: dominio.ErrClienteNaoEncontrado})
+	w.Iniciar()
+
+	for _, topico := range []string{dominio.TopicoGerarRecomendacao, dominio.TopicoGerarRecomendacaoMassa} {
+		handler, ok := bus.handlers[topico]
+		if !ok {
+			t.Fatalf("tópico %s sem assinatura", topico)
+		}
+		if err := handler(map[string]interface{}{"id_cliente": "c1"}); err != nil {
+			t.Errorf("handler de %s: erro = %v", topico, err)
+		}
+	}
+	if len(bus.handlers) != 2 {
+		t.Errorf("tópicos assinados = %d, esperado 2", len(bus.handlers))
+	}
+}
+
+func TestMassaAguardaInterativas(t *testing.T) {
+	repo := &repositorioFake{
+		erro:    dominio.ErrClienteNaoEncontrado,
+		entrou:  make(chan string, 2),
+		liberar: make(chan struct{}),
+	}
+	w, _ := novoWorkerTeste(repo)
+
+	interativa := make(chan error, 1)
+	go func() { interativa <- w.processarInterativo("c1") }()
+	<-repo.entrou
+
+	massa := make(chan error, 1)
+	go func() { massa <- w.processarMassa("c2") }()
+
+	select {
+	case <-massa:
+		t.Fatal("mensagem de massa processada com geração individual em andamento")
+	case id := <-repo.entrou:
+		t.Fatalf("geração de massa do cliente %s iniciada com geração individual em andamento", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(repo.liberar)
+	if err := <-interativa; err != nil {
+		t.Fatalf("interativa: erro = %v", err)
+	}
+
+	select {
+	case err := <-massa:
+		if err != nil {
+			t.Fatalf("massa: erro = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("mensagem de massa não foi liberada ao fim da geração individual")
+	}
+	if id := <-repo.entrou; id != "c2" {
+		t.Errorf("cliente da massa = %s, esperado c2", id)
+	}
+}
+
+func TestMassaSemInterativasNaoAguarda(t *testing.T) {
+	w, _ := novoWorkerTeste(&repositorioFake{erro: dominio.ErrClienteNaoEncontrado})
+
+	massa := make(chan error, 1)
+	go func() { massa <- w.processarMassa("c1") }()
+
+	select {
+	case err := <-massa:
+		if err != nil {
+			t.Fatalf("erro = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("mensagem de massa aguardou sem geração individual em andamento")
+	}
+}
+
+func TestProcessarEvento(t *testing.T) {
+	falhaBanco := errors.New("conexão recusada")
+
+	casos := []struct {
+		nome    string
+		payload interface{}
+		erroRep error
+		erro    error
+	}{
+		{"payload inválido é descartado", 42, falhaBanco, nil},
+		{"cliente vazio é descartado", map[string]interface{}{"id_cliente": ""}, falhaBanco, nil},
+		{"cliente inexistente é descartado", "c1", dominio.ErrClienteNaoEncontrado, nil},
+		{"falha transitória volta para nova entrega", "c1", falhaBanco, falhaBanco},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			w, _ := novoWorkerTeste(&repositorioFake{erro: c.erroRep})
+
+			if err := w.processarEvento(c.payload); !errors.Is(err, c.erro) {
+				t.Errorf("erro = %v, esperado %v", err, c.erro)
+			}
+		})
+	}
+}
+
+func TestDecodificarSolicitacao(t *testing.T) {
+	casos := []struct {
+		nome     string
+		payload  interface{}
+		esperado dominio.SolicitacaoGeracao
+	}{
+		{"formato antigo, só o clienteID", "c1", dominio.SolicitacaoGeracao{ClienteID: "c1"}},
+		{"formato atual", map[string]interface{}{"id_cliente": "c2", "id_job": "j1", "id_lote": "l1"},
+			dominio.SolicitacaoGeracao{ClienteID: "c2", JobID: "j1", LoteID: "l1"}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			sol, err := decodificarSolicitacao(c.payload)
+			if err != nil {
+				t.Fatalf("erro = %v", err)
+			}
+			if sol.ClienteID != c.esperado.ClienteID || sol.JobID != c.esperado.JobID || sol.LoteID != c.esperado.LoteID {
+				t.Errorf("solicitação = %+v, esperado %+v", sol, c.esperado)
+			}
+		})
+	}
+
+	if _, err := decodificarSolicitacao(42); err == nil {
+		t.Error("payload numérico deveria falhar")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -23,6 +24,8 @@ import (
 	docs "backend/docs"
 	"backend/interno/casodeuso"
 	"backend/interno/controladores"
+	"backend/interno/dominio"
+	"backend/interno/infraestrutura/configuracao"
 	"backend/interno/infraestrutura/logger"
 	"backend/interno/infraestrutura/middleware"
 	"backend/interno/infraestrutura/pubsub"
@@ -117,10 +120,48 @@ func main() {
 	}
 	defer eventBus.Close()
 
+	// A geração em massa usa um tópico próprio, consumido com poucas mensagens pendentes
+	// para não disputar o worker com as gerações individuais do app
+	maxPendentesMassa, err := strconv.Atoi(getEnv("MASSA_MAX_MENSAGENS_PENDENTES", "10"))
+	if err != nil || maxPendentesMassa <= 0 {
+		slog.Error("Limite de mensagens pendentes da geração em massa inválido", "valor", os.Getenv("MASSA_MAX_MENSAGENS_PENDENTES"))
+		os.Exit(1)
+	}
+	eventBus.ConfigurarAssinatura(dominio.TopicoGerarRecomendacaoMassa, pubsub.ConfigAssinatura{
+		MaxMensagensPendentes: maxPendentesMassa,
+		Goroutines:            1,
+	})
+
 	// Inicializa repositório e serviços
 	repo := repositorio.NovoRepositorioPostgres(db)
 	servico := casodeuso.NovoServicoRecomendacao(repo, eventBus)
+
+	// Ritmo de publicação da geração em massa (0 não limita)
+	taxaMassa, err := strconv.Atoi(getEnv("MASSA_MENSAGENS_POR_SEGUNDO", "50"))
+	if err != nil {
+		slog.Error("Taxa de publicação da geração em massa inválida", "valor", os.Getenv("MASSA_MENSAGENS_POR_SEGUNDO"))
+		os.Exit(1)
+	}
+	if err := servico.ConfigurarPublicacaoMassa(taxaMassa); err != nil {
+		slog.Error("Taxa de publicação da geração em massa inválida", "erro", err)
+		os.Exit(1)
+	}
 	handler := controladores.NovoControladorRecomendacoes(servico)
+	handlerClientes := controladores.NovoControladorClientes(servico)
+	handlerFeedback := controladores.NovoControladorFeedback(servico)
+	handlerSimulacoes := controladores.NovoControladorSimulacoes(servico)
+	handlerJobs := controladores.NovoControladorJobs(servico)
+	handlerLotes := controladores.NovoControladorLotes(servico)
+
+	// Configuração de pesos/limiares do scoring (recarregada sem reiniciar a API)
+	configPontuacaoPath := getEnv("CONFIG_PONTUACAO_PATH", "config/pontuacao.json")
+	intervaloConfig, err := time.ParseDuration(getEnv("CONFIG_PONTUACAO_INTERVALO", "30s"))
+	if err != nil {
+		slog.Error("Intervalo de recarga da configuração inválido", "erro", err)
+		os.Exit(1)
+	}
+	monitorConfig := configuracao.NovoMonitorConfig(configPontuacaoPath, intervaloConfig, servico.AtualizarConfig)
+	monitorConfig.Iniciar(ctx)
 
 	// Inicializa Firebase Auth
 	firebaseCredentials := getEnv("FIREBASE_CREDENTIALS_PATH", "")
@@ -140,6 +181,9 @@ func main() {
 	workerRecom := worker.NovoWorkerRecomendacao(servico, eventBus)
 	workerRecom.Iniciar()
 
+	// Retoma a publicação de gerações em massa interrompidas por reinício ou por falha durante a execução
+	servico.MonitorarLotes(ctx)
+
 	// Configuração do Gin
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
@@ -168,6 +212,15 @@ func main() {
 		protected.GET("/recomendacoes/:clienteId", handler.BuscarRecomendacoes)
 		protected.POST("/recomendacoes/:clienteId", handler.GerarRecomendacoes)
 		protected.POST("/recomendacoes", handler.GerarRecomendacoesMassiva)
+		protected.GET("/clientes/:id/posicao", handlerClientes.BuscarPosicao)
+		protected.GET("/clientes/:id/supressoes", handlerClientes.ListarSupressoes)
+		protected.DELETE("/clientes/:id/supressoes/:produtoId", handlerClientes.EncerrarSupressao)
+		protected.POST("/recomendacoes/:clienteId/feedback", handlerFeedback.RegistrarFeedback)
+		protected.GET("/feedback/metricas", handlerFeedback.BuscarMetricas)
+		protected.POST("/simulacoes", handlerSimulacoes.Simular)
+		protected.GET("/jobs/:id", handlerJobs.BuscarJob)
+		protected.GET("/lotes/:id", handlerLotes.BuscarLote)
+		protected.POST("/lotes/:id/cancelar", handlerLotes.CancelarLote)
 	}
 
 	// Swagger
@@ -29,6 +29,22 @@ variable "notification_channels" {
   default     = []
 }
 
+variable "massa_mensagens_por_segundo" {
+  description = "Ritmo de publicação da geração em massa (0 não limita)"
+  type        = number
+  default     = 50
+  validation {
+    condition     = var.massa_mensagens_por_segundo >= 0 && var.massa_mensagens_por_segundo <= 1000
+    error_message = "massa_mensagens_por_segundo deve estar entre 0 e 1000."
+  }
+}
+
+variable "massa_max_mensagens_pendentes" {
+  description = "Mensagens de massa em processamento simultâneo por instância"
+  type        = number
+  default     = 10
+}
+
 # Configurações por ambiente
 locals {
   # Sufixo do ambiente
@@ -217,6 +233,14 @@ resource "google_cloud_run_service" "backend" {
           name  = "APP_ENV"
           value = var.environment
         }
+        env {
+          name  = "MASSA_MENSAGENS_POR_SEGUNDO"
+          value = tostring(var.massa_mensagens_por_segundo)
+        }
+        env {
+          name  = "MASSA_MAX_MENSAGENS_PENDENTES"
+          value = tostring(var.massa_max_mensagens_pendentes)
+        }
         env {
           name = "FIREBASE_API_KEY"
           value_from {
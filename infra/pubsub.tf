@@ -52,6 +52,53 @@ resource "google_pubsub_subscription" "gerar_recomendacao_sub" {
   }
 }
 
+# Tópico de baixa prioridade para a geração em massa, separado para não atrasar as gerações individuais
+resource "google_pubsub_topic" "gerar_recomendacao_massa" {
+  name    = "gerar-recomendacao-massa${local.env_suffix}"
+  project = var.gcp_project_id
+
+  message_retention_duration = "86400s" # 24 horas
+
+  labels = {
+    environment = var.environment
+    service     = "recomendacoes"
+    priority    = "low"
+  }
+}
+
+# Subscription para o worker consumir a geração em massa
+resource "google_pubsub_subscription" "gerar_recomendacao_massa_sub" {
+  name    = "${google_pubsub_topic.gerar_recomendacao_massa.name}-sub"
+  topic   = google_pubsub_topic.gerar_recomendacao_massa.name
+  project = var.gcp_project_id
+
+  # O worker segura as mensagens de massa enquanto processa as individuais
+  ack_deadline_seconds = 600
+
+  retry_policy {
+    minimum_backoff = "10s"
+    maximum_backoff = "600s"
+  }
+
+  dynamic "dead_letter_policy" {
+    for_each = var.environment == "prod" ? [1] : []
+    content {
+      dead_letter_topic     = google_pubsub_topic.dlq[0].id
+      max_delivery_attempts = 5
+    }
+  }
+
+  expiration_policy {
+    ttl = "2678400s" # 31 dias
+  }
+
+  labels = {
+    environment = var.environment
+    service     = "recomendacoes"
+    priority    = "low"
+  }
+}
+
 # Dead Letter Queue (DLQ) para mensagens com falha - APENAS PRODUÇÃO
 resource "google_pubsub_topic" "dlq" {
   count   = var.environment == "prod" ? 1 : 0
@@ -100,6 +147,22 @@ resource "google_pubsub_subscription_iam_member" "cloud_run_subscriber" {
   member       = "serviceAccount:${google_service_account.cloudrun_sa.email}"
 }
 
+# IAM: Permissão para Cloud Run publicar a geração em massa
+resource "google_pubsub_topic_iam_member" "cloud_run_publisher_massa" {
+  project = var.gcp_project_id
+  topic   = google_pubsub_topic.gerar_recomendacao_massa.name
+  role    = "roles/pubsub.publisher"
+  member  = "serviceAccount:${google_service_account.cloudrun_sa.email}"
+}
+
+# IAM: Permissão para Cloud Run consumir a geração em massa
+resource "google_pubsub_subscription_iam_member" "cloud_run_subscriber_massa" {
+  project      = var.gcp_project_id
+  subscription = google_pubsub_subscription.gerar_recomendacao_massa_sub.name
+  role         = "roles/pubsub.subscriber"
+  member       = "serviceAccount:${google_service_account.cloudrun_sa.email}"
+}
+
 # Alerta para mensagens na DLQ - APENAS PRODUÇÃO
 resource "google_monitoring_alert_policy" "dlq_messages" {
   count        = var.environment == "prod" ? 1 : 0
@@ -171,6 +234,16 @@ output "pubsub_subscription_name" {
   value       = google_pubsub_subscription.gerar_recomendacao_sub.name
 }
 
+output "pubsub_massa_topic_name" {
+  description = "Nome do tópico Pub/Sub da geração em massa"
+  value       = google_pubsub_topic.gerar_recomendacao_massa.name
+}
+
+output "pubsub_massa_subscription_name" {
+  description = "Nome da subscription Pub/Sub da geração em massa"
+  value       = google_pubsub_subscription.gerar_recomendacao_massa_sub.name
+}
+
 output "pubsub_dlq_topic_name" {
   description = "Nome do tópico DLQ (apenas prod)"
   value       = var.environment == "prod" ? google_pubsub_topic.dlq[0].name : "N/A (DLQ apenas em produção)"
@@ -0,0 +1,2 @@
+-- versão da configuração de pesos/limiares que gerou cada recomendação
+ALTER TABLE recomendacoes ADD COLUMN IF NOT EXISTS versao_config VARCHAR(50);
@@ -0,0 +1,3 @@
+-- índices para as consultas por cliente usadas no scoring (posses e interações)
+CREATE INDEX IF NOT EXISTS idx_transacoes_cliente ON transacoes (id_cliente, id_produto);
+CREATE INDEX IF NOT EXISTS idx_interacoes_cliente ON interacoes (id_cliente, id_produto);
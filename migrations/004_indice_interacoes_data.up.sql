@@ -0,0 +1,2 @@
+-- índice para filtrar as interações do cliente pela janela de tempo do sinal de interesse
+CREATE INDEX IF NOT EXISTS idx_interacoes_cliente_data ON interacoes (id_cliente, data_interacao);
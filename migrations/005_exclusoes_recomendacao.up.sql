@@ -0,0 +1,2 @@
+-- produtos barrados pelos filtros de elegibilidade (suitability), com o motivo de cada exclusão
+ALTER TABLE recomendacoes ADD COLUMN IF NOT EXISTS exclusoes_json JSONB;
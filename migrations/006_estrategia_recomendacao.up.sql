@@ -0,0 +1,6 @@
+-- estratégia de scoring e variante do teste A/B que geraram cada recomendação
+ALTER TABLE recomendacoes ADD COLUMN IF NOT EXISTS estrategia VARCHAR(50);
+ALTER TABLE recomendacoes ADD COLUMN IF NOT EXISTS variante VARCHAR(50);
+
+-- comparação de resultados entre variantes
+CREATE INDEX IF NOT EXISTS idx_recomendacoes_estrategia_variante ON recomendacoes (estrategia, variante, data_geracao);
@@ -0,0 +1,12 @@
+-- reação do cliente a cada item de uma recomendação persistida
+CREATE TABLE IF NOT EXISTS feedback_recomendacoes (
+    id_feedback UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    id_recomendacao UUID NOT NULL REFERENCES recomendacoes(id),
+    id_produto UUID NOT NULL REFERENCES produtos(id_produto),
+    tipo_evento VARCHAR(20) NOT NULL, -- visualizado, clicado, aceito, descartado
+    data_evento TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    regras TEXT[] NOT NULL DEFAULT '{}' -- regras que pontuaram o item, para métricas por regra
+);
+
+CREATE INDEX IF NOT EXISTS idx_feedback_recomendacao ON feedback_recomendacoes (id_recomendacao, id_produto);
+CREATE INDEX IF NOT EXISTS idx_feedback_data ON feedback_recomendacoes (data_evento);
@@ -0,0 +1,13 @@
+-- produtos descartados pelo cliente ficam em carência e não voltam ao topo da recomendação
+CREATE TABLE IF NOT EXISTS supressoes_produto (
+    id_supressao UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    id_cliente UUID NOT NULL REFERENCES clientes(id_cliente),
+    id_produto UUID NOT NULL REFERENCES produtos(id_produto),
+    id_recomendacao UUID REFERENCES recomendacoes(id), -- recomendação em que o item foi descartado
+    data_inicio TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expira_em TIMESTAMP NOT NULL,
+    encerrada_em TIMESTAMP, -- preenchido quando o assessor encerra a carência antes do prazo
+    encerrada_por VARCHAR(128)
+);
+
+CREATE INDEX IF NOT EXISTS idx_supressoes_cliente_ativas ON supressoes_produto (id_cliente, expira_em) WHERE encerrada_em IS NULL;
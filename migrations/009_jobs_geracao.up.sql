@@ -0,0 +1,13 @@
+-- acompanhamento das solicitações de geração de recomendação (pendente, processando, concluido, falhou)
+CREATE TABLE IF NOT EXISTS jobs_geracao (
+    id_job UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    id_cliente UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    iniciado_em TIMESTAMP,
+    finalizado_em TIMESTAMP,
+    erro TEXT,
+    id_recomendacao UUID REFERENCES recomendacoes(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_geracao_cliente ON jobs_geracao (id_cliente, criado_em);
@@ -0,0 +1,12 @@
+-- acompanhamento das gerações em massa (POST /api/v2/recomendacoes)
+CREATE TABLE IF NOT EXISTS lotes_geracao (
+    id_lote UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    status VARCHAR(20) NOT NULL, -- em_andamento, concluido, cancelado
+    opcoes_json JSONB,           -- filtros aplicados a cada cliente do lote
+    total INT NOT NULL DEFAULT 0,
+    publicados INT NOT NULL DEFAULT 0,
+    concluidos INT NOT NULL DEFAULT 0,
+    falhos INT NOT NULL DEFAULT 0,
+    criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finalizado_em TIMESTAMP
+);
@@ -0,0 +1,10 @@
+-- checkpoint da publicação em massa para retomar o lote após reinício
+ALTER TABLE lotes_geracao ADD COLUMN IF NOT EXISTS cursor_cliente UUID;          -- último cliente publicado
+ALTER TABLE lotes_geracao ADD COLUMN IF NOT EXISTS publicacao_finalizada BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE lotes_geracao ADD COLUMN IF NOT EXISTS publicacao_expira_em TIMESTAMP; -- reserva da instância que publica
+
+-- lotes criados antes do checkpoint não têm cursor e não podem ser retomados
+UPDATE lotes_geracao SET publicacao_finalizada = TRUE WHERE publicacao_expira_em IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_lotes_publicacao_pendente ON lotes_geracao (publicacao_expira_em)
+    WHERE status = 'em_andamento' AND NOT publicacao_finalizada;
@@ -0,0 +1,5 @@
+-- segmento de clientes alvo da geração em massa
+ALTER TABLE lotes_geracao ADD COLUMN IF NOT EXISTS segmento_json JSONB;
+
+-- filtro "sem recomendação nos últimos N dias" do segmento
+CREATE INDEX IF NOT EXISTS idx_recomendacoes_cliente_data ON recomendacoes (id_cliente, data_geracao);
@@ -0,0 +1,7 @@
+-- a estimativa de conclusão compara criado_em com o relógio da aplicação; sem fuso horário,
+-- uma sessão do banco em outro fuso distorce o tempo decorrido em horas.
+-- A conversão interpreta os valores existentes no fuso da sessão, o mesmo em que foram gravados.
+ALTER TABLE lotes_geracao
+    ALTER COLUMN criado_em TYPE TIMESTAMPTZ,
+    ALTER COLUMN finalizado_em TYPE TIMESTAMPTZ,
+    ALTER COLUMN publicacao_expira_em TYPE TIMESTAMPTZ;
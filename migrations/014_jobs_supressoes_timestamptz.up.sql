@@ -0,0 +1,12 @@
+-- mesma conversão de 013 para as demais tabelas criadas com TIMESTAMP: os prazos de carência
+-- e o andamento dos jobs também são comparados com o relógio da aplicação.
+-- A conversão interpreta os valores existentes no fuso da sessão, o mesmo em que foram gravados.
+ALTER TABLE jobs_geracao
+    ALTER COLUMN criado_em TYPE TIMESTAMPTZ,
+    ALTER COLUMN iniciado_em TYPE TIMESTAMPTZ,
+    ALTER COLUMN finalizado_em TYPE TIMESTAMPTZ;
+
+ALTER TABLE supressoes_produto
+    ALTER COLUMN data_inicio TYPE TIMESTAMPTZ,
+    ALTER COLUMN expira_em TYPE TIMESTAMPTZ,
+    ALTER COLUMN encerrada_em TYPE TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+-- resultado de cada cliente do lote. A chave impede que uma mensagem reentregue pelo Pub/Sub
+-- conte duas vezes; os contadores de lotes_geracao só mudam quando esta tabela muda.
+-- Lotes em andamento no deploy mantêm os contadores já acumulados e seguem a partir deles.
+CREATE TABLE IF NOT EXISTS resultados_lote (
+    id_lote UUID NOT NULL REFERENCES lotes_geracao(id_lote),
+    id_cliente UUID NOT NULL,
+    sucesso BOOLEAN NOT NULL,
+    registrado_em TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_lote, id_cliente)
+);